@@ -4,14 +4,16 @@
 package bugzilla
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"path"
+	"sort"
 	"strings"
 	"time"
 )
@@ -23,11 +25,46 @@ func (e RequestError) Error() string {
 	return fmt.Sprintf("cannot build request: %v", e.error)
 }
 
-// BugzillaError results from structured Bugzilla errors
-type BugzillaError struct{ error }
+func (e RequestError) Unwrap() error {
+	return e.error
+}
+
+// Errors matching the codes of BugzillaError, to be used with errors.Is()
+var (
+	ErrBugNotFound     = errors.New("bug does not exist")
+	ErrAccessDenied    = errors.New("access denied")
+	ErrInvalidAPIKey   = errors.New("invalid API key")
+	ErrMidAirCollision = errors.New("likely mid-air collision")
+)
+
+// bugzillaErrorCodes maps the numeric error codes of Bugzilla to the errors
+// above
+var bugzillaErrorCodes = map[int]error{
+	101: ErrBugNotFound,
+	102: ErrAccessDenied,
+	306: ErrInvalidAPIKey,
+}
+
+// BugzillaError results from structured Bugzilla errors. Code is zeroed
+// when the response didn't carry a Bugzilla error document.
+type BugzillaError struct {
+	Code          int
+	HTTPStatus    int
+	Documentation string
+	Message       string
+}
 
 func (e BugzillaError) Error() string {
-	return fmt.Sprintf("Bugzilla: %v", e.error)
+	if e.Code == 0 {
+		return fmt.Sprintf("Bugzilla: %s", e.Message)
+	}
+	return fmt.Sprintf("Bugzilla: [%d] %s", e.Code, e.Message)
+}
+
+// Unwrap returns one of the ErrBugNotFound-like errors matching the Bugzilla
+// error code, if any
+func (e BugzillaError) Unwrap() error {
+	return bugzillaErrorCodes[e.Code]
 }
 
 // ConnectionError happens when performing the request
@@ -37,6 +74,10 @@ func (e ConnectionError) Error() string {
 	return fmt.Sprintf("cannot communicate with server: %v", e.error)
 }
 
+func (e ConnectionError) Unwrap() error {
+	return e.error
+}
+
 // DecodeErrror happens when it fails to parse JSON from the responses
 type DecodeErrror struct{ error }
 
@@ -44,20 +85,39 @@ func (e DecodeErrror) Error() string {
 	return fmt.Sprintf("Error decoding response: %v", e.error)
 }
 
+func (e DecodeErrror) Unwrap() error {
+	return e.error
+}
+
 // Cacher should be anything that takes the name of the object to be cached
 // and returns something that can receive writes with the contents and then
-// eventually be closed.
+// eventually be closed. Only bugs fetched with their comments and
+// attachments are written.
 type Cacher interface {
 	GetWriter(id string) io.WriteCloser
 }
 
-// Config sets the parameters needed to set up the client. Cacher can be
-// left zeroed.
+// Config sets the parameters needed to set up the client. Cacher, Retry and
+// the rate limit can be left zeroed.
 type Config struct {
 	BaseURL  string
 	Username string
 	ApiKey   string
 	Cacher   Cacher
+	Retry    RetryPolicy
+
+	// CacheMaxAge is for how long bugs read back from the cache are used
+	// without checking the server. Only used when Cacher also implements
+	// CacheReader.
+	CacheMaxAge time.Duration
+	// CacheRevalidate allows using cached bugs older than CacheMaxAge
+	// when the server reports they have not changed since then
+	CacheRevalidate bool
+
+	// RateLimit is the maximum number of requests per second sent to the
+	// server, with bursts of up to RateBurst requests
+	RateLimit float64
+	RateBurst int
 }
 
 func (c *Config) emailAddress() (string, error) {
@@ -74,6 +134,7 @@ type Client struct {
 	Config        Config
 	seriousClient *http.Client
 	cacher        Cacher
+	limiter       *rateLimiter
 }
 
 func getHTTPClient(config *Config) *http.Client {
@@ -90,6 +151,9 @@ func getHTTPClient(config *Config) *http.Client {
 func New(config Config) (*Client, error) {
 	httpClient := getHTTPClient(&config)
 	client := &Client{Config: config, seriousClient: httpClient, cacher: config.Cacher}
+	if config.RateLimit > 0 {
+		client.limiter = newRateLimiter(config.RateLimit, config.RateBurst, nil)
+	}
 	return client, nil
 }
 
@@ -130,6 +194,35 @@ func (c *Client) makeURL(base_path string, values *url.Values) (string, error) {
 	return url.String(), nil
 }
 
+// escapeSegment escapes a path segment given by the user, including "."
+// and "..", so that it can't point to a different endpoint
+func escapeSegment(segment string) string {
+	if segment == "." || segment == ".." {
+		return strings.Replace(segment, ".", "%2E", -1)
+	}
+	return url.PathEscape(segment)
+}
+
+// makeSegmentsURL is makeURL for base_path followed by segments, such as
+// product names, which are escaped
+func (c *Client) makeSegmentsURL(base_path string, segments []string, values *url.Values) (string, error) {
+	u, err := url.Parse(c.Config.BaseURL)
+	if err != nil {
+		return "", RequestError{err}
+	}
+	c.addAuthOptions(values)
+	u.RawQuery = values.Encode()
+	u.Path = path.Join(u.Path, base_path)
+	rawPath := u.EscapedPath()
+	for _, segment := range segments {
+		u.Path += "/" + segment
+		rawPath += "/" + escapeSegment(segment)
+	}
+	u.RawPath = rawPath
+
+	return u.String(), nil
+}
+
 func valuesFromBugIds(ids []int) (first int, values *url.Values, err error) {
 	values = &url.Values{}
 	if len(ids) < 1 {
@@ -288,16 +381,7 @@ func (b *Bug) hasNeedinfoFor(email string) bool {
 
 // findNeedinfosFor gets the ids of the needinfos for a given email
 func (b *Bug) findNeedinfosFor(email string) []int {
-	ids := make([]int, 0, 0)
-	lowerEmail := strings.ToLower(email)
-	for _, flag := range b.Flags {
-		if flag.Name == "needinfo" {
-			if lowerEmail == "" || strings.ToLower(flag.Requestee) == lowerEmail {
-				ids = append(ids, flag.ID)
-			}
-		}
-	}
-	return ids
+	return flagIDs(b.FindFlags(FlagFilter{Name: "needinfo", Requestee: email}))
 }
 
 // Attachment as provided by the bug information page. This struct has only
@@ -356,11 +440,15 @@ type Comment struct {
 	Time         time.Time `json:"time"`
 	CreationTime time.Time `json:"creation_time"`
 	IsPrivate    bool      `json:"is_private"`
+	Tags         []string  `json:"tags"`
 }
 
+// Fault is the error reported for one of the bugs requested, when the
+// request is made with permissive=1
 type Fault struct {
-	code    int
-	message string
+	ID      int    `json:"id"`
+	Code    int    `json:"faultCode"`
+	Message string `json:"faultString"`
 }
 
 type responseError struct {
@@ -528,15 +616,22 @@ func (c *Client) cacheBugs(bugs []Bug) {
 	}
 }
 
-func (c *Client) attemptDecodingError(body []byte) (msg string, ok bool) {
+func (c *Client) decodeError(status int, body []byte) BugzillaError {
+	bzErr := BugzillaError{HTTPStatus: status, Message: http.StatusText(status)}
 	var result responseError
 	err := json.Unmarshal(body, &result)
 	if err == nil && result.Message != nil {
-		msg = fmt.Sprintf("[%d] %s", *result.Code, *result.Message)
-		ok = true
+		bzErr.Message = *result.Message
+		if result.Code != nil {
+			bzErr.Code = *result.Code
+		}
+		if result.Documentation != nil {
+			bzErr.Documentation = *result.Documentation
+		}
 	}
-	return
+	return bzErr
 }
+
 func (c *Client) collect(resp *http.Response, err error) ([]byte, error) {
 	if resp == nil && err != nil {
 		return nil, ConnectionError{err}
@@ -551,48 +646,54 @@ func (c *Client) collect(resp *http.Response, err error) ([]byte, error) {
 	}
 
 	if !(resp.StatusCode >= 200 && resp.StatusCode <= 299) {
-		msg, ok := c.attemptDecodingError(body)
-		if ok {
-			err = fmt.Errorf(msg)
-		} else {
-			err = fmt.Errorf(http.StatusText(resp.StatusCode))
-		}
-		return nil, BugzillaError{err}
+		return nil, c.decodeError(resp.StatusCode, body)
 	}
 
 	return body, nil
 }
 
-func (c *Client) fetch(url string) ([]byte, error) {
-	resp, err := c.seriousClient.Get(url)
-	return c.collect(resp, err)
+func (c *Client) fetch(ctx context.Context, url string) ([]byte, error) {
+	return c.do(ctx, http.MethodGet, url, nil)
 }
 
-func (c *Client) put(url string, content_type string, body []byte) ([]byte, error) {
-	request, err := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(body))
-	if err != nil {
-		return nil, err
-	}
-	resp, err := c.seriousClient.Do(request)
-	return c.collect(resp, err)
+func (c *Client) put(ctx context.Context, url string, content_type string, body []byte) ([]byte, error) {
+	return c.do(ctx, http.MethodPut, url, body)
+}
+
+func (c *Client) post(ctx context.Context, url string, content_type string, body []byte) ([]byte, error) {
+	return c.do(ctx, http.MethodPost, url, body)
+}
+
+// GetComments returns the comments of the given bugs
+func (c *Client) GetComments(bugIds []int) ([]Comment, error) {
+	return c.GetCommentsContext(context.Background(), bugIds)
 }
 
-func (c *Client) post(url string, content_type string, body []byte) ([]byte, error) {
-	request, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(body))
+// GetCommentsContext is GetComments with a context.Context
+func (c *Client) GetCommentsContext(ctx context.Context, bugIds []int) ([]Comment, error) {
+	cached, missing := c.cachedComments(bugIds)
+	if len(missing) == 0 {
+		return joinComments(bugIds, cached), nil
+	}
+	comments, err := c.fetchComments(ctx, missing)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.seriousClient.Do(request)
-	return c.collect(resp, err)
+	for _, comment := range comments {
+		cached[comment.BugID] = append(cached[comment.BugID], comment)
+	}
+	return joinComments(bugIds, cached), nil
 }
 
-func (c *Client) GetComments(bugIds []int) ([]Comment, error) {
+// fetchComments gets the comments of bugIds from the server, bypassing the
+// cache
+func (c *Client) fetchComments(ctx context.Context, bugIds []int) ([]Comment, error) {
 	params := map[string]string{}
 	url, err := c.getCommentsURL(bugIds, params)
 	if err != nil {
 		return nil, err
 	}
-	body, err := c.fetch(url)
+	body, err := c.fetch(ctx, url)
 	if err != nil {
 		return nil, err
 	}
@@ -600,17 +701,40 @@ func (c *Client) GetComments(bugIds []int) ([]Comment, error) {
 	if err != nil {
 		return nil, err
 	}
+	c.cacheComments(bugIds, comments)
 	return comments, nil
 }
 
 // GetAttachmentsInfo returns information about attachments in a bug -- with no data
 func (c *Client) GetAttachmentsInfo(bugIds []int) ([]Attachment, error) {
+	return c.GetAttachmentsInfoContext(context.Background(), bugIds)
+}
+
+// GetAttachmentsInfoContext is GetAttachmentsInfo with a context.Context
+func (c *Client) GetAttachmentsInfoContext(ctx context.Context, bugIds []int) ([]Attachment, error) {
+	cached, missing := c.cachedAttachments(bugIds)
+	if len(missing) == 0 {
+		return joinAttachments(bugIds, cached), nil
+	}
+	attachments, err := c.fetchAttachmentsInfo(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	for _, attachment := range attachments {
+		cached[attachment.BugId] = append(cached[attachment.BugId], attachment)
+	}
+	return joinAttachments(bugIds, cached), nil
+}
+
+// fetchAttachmentsInfo gets the attachments information of bugIds from the
+// server, bypassing the cache
+func (c *Client) fetchAttachmentsInfo(ctx context.Context, bugIds []int) ([]Attachment, error) {
 	params := map[string]string{"exclude_fields": "data"}
 	url, err := c.getAttachmentsURL(bugIds, params)
 	if err != nil {
 		return nil, err
 	}
-	body, err := c.fetch(url)
+	body, err := c.fetch(ctx, url)
 	if err != nil {
 		return nil, err
 	}
@@ -618,17 +742,23 @@ func (c *Client) GetAttachmentsInfo(bugIds []int) ([]Attachment, error) {
 	if err != nil {
 		return nil, err
 	}
+	c.cacheAttachments(bugIds, attachments)
 	return attachments, nil
 }
 
 // GetAttachment returns one attachment (including its data)
 func (c *Client) GetAttachment(id int) (*Attachment, error) {
+	return c.GetAttachmentContext(context.Background(), id)
+}
+
+// GetAttachmentContext is GetAttachment with a context.Context
+func (c *Client) GetAttachmentContext(ctx context.Context, id int) (*Attachment, error) {
 	params := map[string]string{}
 	url, err := c.getAttachmentURL(id, params)
 	if err != nil {
 		return nil, err
 	}
-	body, err := c.fetch(url)
+	body, err := c.fetch(ctx, url)
 	if err != nil {
 		return nil, err
 	}
@@ -643,12 +773,37 @@ func (c *Client) GetAttachment(id int) (*Attachment, error) {
 	return attachment, nil
 }
 
+// GetBug gets a *Bug with its comments and attachments
 func (c *Client) GetBug(id int) (*Bug, error) {
 	return c.GetBugEx(id, true, true)
 }
 
-// GetBug gets a *Bug from the Bugzilla API (apibuzilla)
+// GetBugContext is GetBug with a context.Context
+func (c *Client) GetBugContext(ctx context.Context, id int) (*Bug, error) {
+	return c.GetBugExContext(ctx, id, true, true)
+}
+
+// GetBugEx gets a *Bug from the Bugzilla API (apibuzilla)
 func (c *Client) GetBugEx(id int, withComments bool, withAttachments bool) (*Bug, error) {
+	return c.GetBugExContext(context.Background(), id, withComments, withAttachments)
+}
+
+// GetBugExContext is GetBugEx with a context.Context
+func (c *Client) GetBugExContext(ctx context.Context, id int, withComments bool, withAttachments bool) (*Bug, error) {
+	if bug, ok := c.cachedBug(ctx, id); ok {
+		if !withComments {
+			bug.Comments = make([]Comment, 0, 0)
+		}
+		if !withAttachments {
+			bug.Attachments = make([]Attachment, 0, 0)
+		}
+		return bug, nil
+	}
+	return c.fetchBug(ctx, id, withComments, withAttachments)
+}
+
+// fetchBug gets a bug from the server, bypassing the cache
+func (c *Client) fetchBug(ctx context.Context, id int, withComments bool, withAttachments bool) (*Bug, error) {
 	// query.Set("ctype", "xml")
 	// query.Set("excludefield", "attachmentdata")
 	params := map[string]string{}
@@ -656,7 +811,7 @@ func (c *Client) GetBugEx(id int, withComments bool, withAttachments bool) (*Bug
 	if err != nil {
 		return nil, err
 	}
-	bugsBody, err := c.fetch(url)
+	bugsBody, err := c.fetch(ctx, url)
 	if err != nil {
 		return nil, err
 	}
@@ -667,14 +822,16 @@ func (c *Client) GetBugEx(id int, withComments bool, withAttachments bool) (*Bug
 
 	comments := make([]Comment, 0, 0)
 	attachments := make([]Attachment, 0, 0)
+	// The bug is being fetched because it may have changed, so its cached
+	// comments and attachments can't be trusted either
 	if withComments {
-		comments, err = c.GetComments([]int{id})
+		comments, err = c.fetchComments(ctx, []int{id})
 		if err != nil {
 			return nil, ConnectionError{err}
 		}
 	}
 	if withAttachments {
-		attachments, err = c.GetAttachmentsInfo([]int{id})
+		attachments, err = c.fetchAttachmentsInfo(ctx, []int{id})
 		if err != nil {
 			return nil, ConnectionError{err}
 		}
@@ -685,13 +842,156 @@ func (c *Client) GetBugEx(id int, withComments bool, withAttachments bool) (*Bug
 		bugs[i].Attachments = attachments
 	}
 
-	c.cacheBugs(bugs)
+	if withComments && withAttachments {
+		c.cacheBugs(bugs)
+	}
 
 	bug := &bugs[0]
 
 	return bug, err
 }
 
+// DefaultChunkSize is the maximum number of bug IDs sent in a single
+// request by GetBugs when GetBugsOptions.ChunkSize is not set
+const DefaultChunkSize = 100
+
+// GetBugsOptions sets what GetBugs fetches along with the bugs
+type GetBugsOptions struct {
+	WithComments    bool
+	WithAttachments bool
+	// ChunkSize is the maximum number of bugs handled by each request, to
+	// keep URLs short
+	ChunkSize int
+}
+
+// chunkIds splits ids in slices of at most size elements, dropping
+// duplicates
+func chunkIds(ids []int, size int) [][]int {
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+	seen := make(map[int]bool, len(ids))
+	chunks := make([][]int, 0, len(ids)/size+1)
+	var chunk []int
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		chunk = append(chunk, id)
+		if len(chunk) == size {
+			chunks = append(chunks, chunk)
+			chunk = nil
+		}
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// MissingBugsError is returned by GetBugs, along with the bugs found, when
+// some of the bugs could not be fetched, as when they don't exist or are
+// not visible to the user. Errors has a BugzillaError for each of them.
+type MissingBugsError struct {
+	Errors map[int]error
+}
+
+func (e *MissingBugsError) Error() string {
+	ids := make([]int, 0, len(e.Errors))
+	for id := range e.Errors {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return fmt.Sprintf("Cannot get %d bug(s), bug %d: %v", len(ids), ids[0], e.Errors[ids[0]])
+}
+
+// GetBugs gets many bugs using as few requests as possible. The bugs are
+// returned in the same order of ids.
+//
+// A missing or private bug doesn't fail the request of its chunk: the other
+// bugs are still returned, with a *MissingBugsError telling which ones were
+// left out.
+func (c *Client) GetBugs(ids []int, opts GetBugsOptions) ([]Bug, error) {
+	return c.GetBugsContext(context.Background(), ids, opts)
+}
+
+// GetBugsContext is GetBugs with a context.Context
+func (c *Client) GetBugsContext(ctx context.Context, ids []int, opts GetBugsOptions) ([]Bug, error) {
+	byID := make(map[int]*Bug, len(ids))
+	missing := make(map[int]error)
+	for _, chunk := range chunkIds(ids, opts.ChunkSize) {
+		url, err := c.getBugsURL(chunk, map[string]string{"permissive": "1"})
+		if err != nil {
+			return nil, err
+		}
+		bugsBody, err := c.fetch(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		var bugsFound bugsResult
+		if err := json.Unmarshal(bugsBody, &bugsFound); err != nil {
+			return nil, DecodeErrror{err}
+		}
+		for _, fault := range bugsFound.Faults {
+			missing[fault.ID] = BugzillaError{Code: fault.Code, Message: fault.Message}
+		}
+		bugs := bugsFound.Bugs
+		found := make([]int, 0, len(bugs))
+		for i := range bugs {
+			bugs[i].Comments = make([]Comment, 0, 0)
+			bugs[i].Attachments = make([]Attachment, 0, 0)
+			byID[bugs[i].ID] = &bugs[i]
+			found = append(found, bugs[i].ID)
+		}
+		if len(found) == 0 {
+			continue
+		}
+
+		// As in fetchBug, the cached comments and attachments may be older
+		// than the bugs just fetched
+		if opts.WithComments {
+			comments, err := c.fetchComments(ctx, found)
+			if err != nil {
+				return nil, ConnectionError{err}
+			}
+			for _, comment := range comments {
+				if bug, ok := byID[comment.BugID]; ok {
+					bug.Comments = append(bug.Comments, comment)
+				}
+			}
+		}
+		if opts.WithAttachments {
+			attachments, err := c.fetchAttachmentsInfo(ctx, found)
+			if err != nil {
+				return nil, ConnectionError{err}
+			}
+			for _, attachment := range attachments {
+				if bug, ok := byID[attachment.BugId]; ok {
+					bug.Attachments = append(bug.Attachments, attachment)
+				}
+			}
+		}
+	}
+
+	result := make([]Bug, 0, len(byID))
+	for _, id := range ids {
+		if bug, ok := byID[id]; ok {
+			result = append(result, *bug)
+			delete(byID, id)
+		}
+	}
+
+	if opts.WithComments && opts.WithAttachments {
+		c.cacheBugs(result)
+	}
+
+	if len(missing) > 0 {
+		return result, &MissingBugsError{Errors: missing}
+	}
+	return result, nil
+}
+
 // ErrBugzilla is an error from Bugzilla
 type ErrBugzilla struct{ error }
 
@@ -699,6 +999,10 @@ func (e ErrBugzilla) Error() string {
 	return fmt.Sprintf("Error from Bugzilla: %v", e.error)
 }
 
+func (e ErrBugzilla) Unwrap() error {
+	return e.error
+}
+
 // PriorityMap maps short priority names to the longer ones, as provided by
 // the Web Interface
 var PriorityMap = map[string]string{
@@ -738,6 +1042,9 @@ type Changes struct {
 	// DeltaTS should have the timestamp of the last change
 	DeltaTS      time.Time
 	CheckDeltaTS bool
+	// ServerDeltaTS, with CheckDeltaTS, also has the server check DeltaTS,
+	// atomically with the update, failing with a *MidAirCollisionError
+	ServerDeltaTS bool
 }
 
 type updateResponse struct {
@@ -759,16 +1066,16 @@ type UpdateResponse struct {
 func (c *Client) checkDeltaTS(changes *Changes, bug *Bug) error {
 	if changes.CheckDeltaTS {
 		if !bug.LastChangeTime.Equal(changes.DeltaTS) {
-			return ErrBugzilla{fmt.Errorf("likely mid-air collision: the bug has been updated at %v", bug.LastChangeTime)}
+			return ErrBugzilla{fmt.Errorf("%w: the bug has been updated at %v", ErrMidAirCollision, bug.LastChangeTime)}
 		}
 	}
 	return nil
 }
 
 type updOp struct {
-	Add    []string `json:"add,omitempty"`
-	Remove []string `json:"remove,omitempty"`
-	Set    []string `json:"set,omitempty"`
+	Add    []string  `json:"add,omitempty"`
+	Remove []string  `json:"remove,omitempty"`
+	Set    *[]string `json:"set,omitempty"`
 }
 
 func (u *updOp) AddOp(what string) {
@@ -780,7 +1087,10 @@ func (u *updOp) RemoveOp(what string) {
 }
 
 func (u *updOp) SetOp(what string) {
-	u.Set = append(u.Set, what)
+	if u.Set == nil {
+		u.Set = &[]string{}
+	}
+	*u.Set = append(*u.Set, what)
 }
 
 type flagChange struct {
@@ -797,18 +1107,24 @@ type commentChange struct {
 	IsPrivate bool   `json:"is_private"`
 }
 
+type updIntOp struct {
+	Add    []int  `json:"add,omitempty"`
+	Remove []int  `json:"remove,omitempty"`
+	Set    *[]int `json:"set,omitempty"`
+}
+
 type updBug struct {
 	Ids []int `json:"ids"`
 
-	Alias     []updOp      `json:"alias,omitempty"`
-	Blocks    []updOp      `json:"blocks,omitempty"`
+	Alias     *updOp       `json:"alias,omitempty"`
+	Blocks    *updIntOp    `json:"blocks,omitempty"`
 	CC        *updOp       `json:"cc,omitempty"`
 	CCDetail  []updOp      `json:"cc_detail,omitempty"`
-	DependsOn []updOp      `json:"depends_on,omitempty"`
+	DependsOn *updIntOp    `json:"depends_on,omitempty"`
 	Flags     []flagChange `json:"flags,omitempty"`
-	Groups    []updOp      `json:"groups,omitempty"`
-	Keywords  []updOp      `json:"keywords,omitempty"`
-	SeeAlso   []updOp      `json:"see_also,omitempty"`
+	Groups    *updOp       `json:"groups,omitempty"`
+	Keywords  *updOp       `json:"keywords,omitempty"`
+	SeeAlso   *updOp       `json:"see_also,omitempty"`
 
 	IsCCAccessible      *bool `json:"is_cc_accessible,omitempty"`
 	IsCreatorAccessible *bool `json:"is_creator_accessible,omitempty"`
@@ -834,11 +1150,13 @@ type updBug struct {
 	Version         *string        `json:"version,omitempty"`
 	Whiteboard      *string        `json:"whiteboard,omitempty"`
 
-	// Not implemented for now:
-	//ActualTime          float64 `json:"actual_time,omitempty"`
-	//Deadline            string  `json:"deadline,omitempty"`
-	//EstimatedTime       float64 `json:"estimated_time,omitempty"`
-	//RemainingTime       float64 `json:"remaining_time,omitempty"`
+	Deadline      *string  `json:"deadline,omitempty"`
+	EstimatedTime *float64 `json:"estimated_time,omitempty"`
+	RemainingTime *float64 `json:"remaining_time,omitempty"`
+	WorkTime      *float64 `json:"work_time,omitempty"`
+
+	DeltaTS     *string `json:"delta_ts,omitempty"`
+	UpdateToken *string `json:"update_token,omitempty"`
 }
 
 func newBugUpdate() *updBug {
@@ -870,7 +1188,21 @@ func (u *updBug) RemoveCC(email string) {
 // Update changes a bug with the attribute to be modified provided by
 // Changes
 func (c *Client) Update(id int, changes Changes) (updateResponse *UpdateResponse, err error) {
-	bug, err := c.GetBugEx(id, false, false)
+	return c.UpdateContext(context.Background(), id, changes)
+}
+
+// UpdateContext is Update with a context.Context
+func (c *Client) UpdateContext(ctx context.Context, id int, changes Changes) (*UpdateResponse, error) {
+	up, err := c.buildUpdate(ctx, id, changes)
+	if err != nil {
+		return nil, err
+	}
+	return c.putUpdate(ctx, up)
+}
+
+// buildUpdate fetches the bug and computes the update for changes
+func (c *Client) buildUpdate(ctx context.Context, id int, changes Changes) (up *updBug, err error) {
+	bug, err := c.fetchBug(ctx, id, false, false)
 	if err != nil {
 		return
 	}
@@ -879,7 +1211,7 @@ func (c *Client) Update(id int, changes Changes) (updateResponse *UpdateResponse
 		return
 	}
 
-	up := newBugUpdate()
+	up = newBugUpdate()
 	up.Ids = append(up.Ids, id)
 
 	if changes.AddComment != "" {
@@ -959,31 +1291,46 @@ func (c *Client) Update(id int, changes Changes) (updateResponse *UpdateResponse
 	if changes.SetDuplicate != 0 {
 		up.DupeOf = changes.SetDuplicate
 	}
+	if changes.CheckDeltaTS && changes.ServerDeltaTS {
+		deltaTS := changes.DeltaTS.UTC().Format(searchTimeFormat)
+		up.DeltaTS = &deltaTS
+		if bug.UpdateToken != "" {
+			up.UpdateToken = &bug.UpdateToken
+		}
+	}
 
-	url, err := c.getUpdateBugsURL([]int{id}, map[string]string{})
+	return up, nil
+}
+
+// putUpdate sends up, which must have a single bug in Ids
+func (c *Client) putUpdate(ctx context.Context, up *updBug) (*UpdateResponse, error) {
+	updateResponses, err := c.putUpdates(ctx, up)
 	if err != nil {
-		return
+		return nil, err
 	}
-	updBody, err := c.encodeUpdate(up)
+	if len(updateResponses) != 1 {
+		return nil, fmt.Errorf("Got an unexpected number of update responses: %v", updateResponses)
+	}
+	return &updateResponses[0], nil
+}
+
+func (c *Client) putUpdates(ctx context.Context, up *updBug) ([]UpdateResponse, error) {
+	request, err := c.updateRequest(up)
 	if err != nil {
-		return
+		return nil, err
 	}
-	resp, err := c.put(url, "application/json", updBody)
+	resp, err := c.put(ctx, request.URL, "application/json", request.Body)
 	if err != nil {
-		return
+		return nil, c.midAirCollision(ctx, up, err)
 	}
 	updateResponses, err := c.decodeUpdateResponse(resp)
 	if err != nil {
-		return
+		return nil, err
 	}
-
-	if len(updateResponses) != 1 {
-		return nil, fmt.Errorf("Got an unexpected number of update responses: %v", updateResponses)
+	for i := range updateResponses {
+		c.updateCachedBug(&updateResponses[i], up.Comment != nil)
 	}
-
-	updateResponse = &updateResponses[0]
-
-	return
+	return updateResponses, nil
 }
 
 type AttachmentDownload struct {
@@ -1011,15 +1358,21 @@ func (a *AttachmentDownload) DataFromDownload(data []byte) ([]byte, error) {
 // Returns an Attachment with only the Size and Filename filled, a reader
 // and error.
 func (c *Client) DownloadAttachment(id int) (*AttachmentDownload, io.ReadCloser, error) {
+	return c.DownloadAttachmentContext(context.Background(), id)
+}
+
+// DownloadAttachmentContext is DownloadAttachment with a context.Context. The
+// context also covers reading the returned io.ReadCloser.
+func (c *Client) DownloadAttachmentContext(ctx context.Context, id int) (*AttachmentDownload, io.ReadCloser, error) {
 	params := map[string]string{"includefields": "data"}
 	url, err := c.getAttachmentURL(id, params)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	resp, err := c.seriousClient.Get(url)
+	resp, err := c.send(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return nil, nil, ConnectionError{err}
+		return nil, nil, err
 	}
 	ad := &AttachmentDownload{client: c, id: id}
 	return ad, resp.Body, nil
@@ -1053,6 +1406,11 @@ func (c *Client) decodePostAttachment(data []byte) (int, error) {
 
 // UploadAttachment posts a new attachment to a given bug
 func (c *Client) UploadAttachment(bugId int, attachment *PostAttachment) (id int, err error) {
+	return c.UploadAttachmentContext(context.Background(), bugId, attachment)
+}
+
+// UploadAttachmentContext is UploadAttachment with a context.Context
+func (c *Client) UploadAttachmentContext(ctx context.Context, bugId int, attachment *PostAttachment) (id int, err error) {
 	params := map[string]string{}
 	url, err := c.getAttachmentsURL([]int{bugId}, params)
 	if err != nil {
@@ -1066,10 +1424,119 @@ func (c *Client) UploadAttachment(bugId int, attachment *PostAttachment) (id int
 		return 0, err
 	}
 
-	resp, err := c.post(url, "application/json", encoded)
+	resp, err := c.post(ctx, url, "application/json", encoded)
 	if err != nil {
 		return 0, err
 	}
 	id, err = c.decodePostAttachment(resp)
+	if err == nil {
+		c.invalidateBug(bugId, CacheComments, CacheAttachments)
+	}
 	return
 }
+
+// NewFlag describes a flag to be set when creating bugs or attachments
+type NewFlag struct {
+	Name      string `json:"name,omitempty"`
+	TypeID    int    `json:"type_id,omitempty"`
+	Status    string `json:"status"`
+	Requestee string `json:"requestee,omitempty"`
+}
+
+// NewBug describes a bug to be filed by CreateBug. Custom fields (such as
+// cf_foundby) can be provided in CustomFields and are sent along with the
+// other fields.
+type NewBug struct {
+	Product     string    `json:"product"`
+	Component   string    `json:"component"`
+	Version     string    `json:"version"`
+	Summary     string    `json:"summary"`
+	Description string    `json:"description,omitempty"`
+	OpSys       string    `json:"op_sys,omitempty"`
+	Platform    string    `json:"platform,omitempty"`
+	Priority    string    `json:"priority,omitempty"`
+	Severity    string    `json:"severity,omitempty"`
+	AssignedTo  string    `json:"assigned_to,omitempty"`
+	QAContact   string    `json:"qa_contact,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	URL         string    `json:"url,omitempty"`
+	Whiteboard  string    `json:"whiteboard,omitempty"`
+	Alias       []string  `json:"alias,omitempty"`
+	CC          []string  `json:"cc,omitempty"`
+	Keywords    []string  `json:"keywords,omitempty"`
+	Groups      []string  `json:"groups,omitempty"`
+	Flags       []NewFlag `json:"flags,omitempty"`
+
+	DescriptionIsPrivate bool `json:"comment_is_private,omitempty"`
+
+	CustomFields map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON merges CustomFields into the encoded bug
+func (b NewBug) MarshalJSON() ([]byte, error) {
+	type plainNewBug NewBug
+	encoded, err := json.Marshal(plainNewBug(b))
+	if err != nil || len(b.CustomFields) == 0 {
+		return encoded, err
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+	for k, v := range b.CustomFields {
+		fields[k] = v
+	}
+	return json.Marshal(fields)
+}
+
+type createBugResponse struct {
+	ID int `json:"id"`
+}
+
+func (c *Client) getCreateBugURL() (string, error) {
+	return c.makeURL("/rest/bug", &url.Values{})
+}
+
+func (c *Client) encodeNewBug(bug *NewBug) ([]byte, error) {
+	b, err := json.Marshal(bug)
+	if err != nil {
+		return nil, RequestError{fmt.Errorf("Cannot build new bug: %v", err)}
+	}
+	return b, nil
+}
+
+func (c *Client) decodeCreateBug(data []byte) (int, error) {
+	var result createBugResponse
+
+	err := json.Unmarshal(data, &result)
+	if err != nil {
+		return 0, DecodeErrror{err}
+	}
+	if result.ID == 0 {
+		return 0, DecodeErrror{fmt.Errorf("no bug ID in the response")}
+	}
+
+	return result.ID, nil
+}
+
+// CreateBug files a new bug and returns its ID
+func (c *Client) CreateBug(bug NewBug) (id int, err error) {
+	return c.CreateBugContext(context.Background(), bug)
+}
+
+// CreateBugContext is CreateBug with a context.Context
+func (c *Client) CreateBugContext(ctx context.Context, bug NewBug) (id int, err error) {
+	url, err := c.getCreateBugURL()
+	if err != nil {
+		return 0, err
+	}
+	encoded, err := c.encodeNewBug(&bug)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.post(ctx, url, "application/json", encoded)
+	if err != nil {
+		return 0, err
+	}
+	return c.decodeCreateBug(resp)
+}
@@ -2,7 +2,9 @@ package bugzilla_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -1024,6 +1026,39 @@ func (cs *clientSuite) TestGetBugNotPermitted(c *C) {
 	c.Assert(err, ErrorMatches, ".*You are not authorized to access bug.*")
 }
 
+func (cs *clientSuite) TestBugzillaErrorCodes(c *C) {
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, sampleError, http.StatusBadRequest)
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+	_, err := bz.GetBug(1047068)
+
+	var bzErr bugzilla.BugzillaError
+	c.Assert(errors.As(err, &bzErr), Equals, true)
+	c.Check(bzErr.Code, Equals, 102)
+	c.Check(bzErr.HTTPStatus, Equals, http.StatusBadRequest)
+	c.Check(bzErr.Documentation, Equals, "https://bugzilla.readthedocs.org/en/5.0/api/")
+	c.Check(bzErr.Message, Equals, "You are not authorized to access bug #1171184.")
+	c.Check(errors.Is(err, bugzilla.ErrAccessDenied), Equals, true)
+	c.Check(errors.Is(err, bugzilla.ErrBugNotFound), Equals, false)
+
+	// The mid-air collision check can also be matched
+	ts1, _, nextJson, _ := makeBugzillaServerWithChannels()
+	defer ts1.Close()
+	bz = makeClient(ts1.URL)
+	nextJson <- bugsJson
+	changes := bugzilla.Changes{DeltaTS: time.Now(), CheckDeltaTS: true}
+	_, err = bz.Update(101234, changes)
+	c.Check(errors.Is(err, bugzilla.ErrMidAirCollision), Equals, true)
+
+	// Transport errors can be unwrapped too
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = bz.GetBugContext(ctx, 101234)
+	c.Check(errors.Is(err, context.Canceled), Equals, true)
+}
+
 // A copy of bugsJson, modified to have only one bug document
 var sampleJSON = `
 {
@@ -1283,3 +1318,142 @@ func (cs *clientSuite) TestUploadAttachment(c *C) {
 	ja := jsonassert.New(c)
 	ja.Assertf(query, `{"ids":[1047068],"data":"YQo=","file_name": "filename.txt", "content_type": "text/plain","summary": "some summary"}`)
 }
+
+func (cs *clientSuite) TestCreateBug(c *C) {
+	ts0, queries, _, processBug := makeBugzillaServerWithChannels()
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	processBug <- `{"id": 1050001}`
+
+	bug := bugzilla.NewBug{
+		Product:     "Enterprise Frobnicator 9000.1",
+		Component:   "Basesystem",
+		Version:     "FROB90001Maint-Upd",
+		Summary:     "It crashed",
+		Description: "Backtrace follows",
+		Keywords:    []string{"TRETA"},
+		Flags:       []bugzilla.NewFlag{{Name: "needinfo", Status: "?", Requestee: "user1@foobarcorp.example.com"}},
+		CustomFields: map[string]interface{}{
+			"cf_foundby": "i18n Test",
+		},
+	}
+	id, err := bz.CreateBug(bug)
+	c.Assert(err, IsNil)
+	c.Assert(id, Equals, 1050001)
+
+	query := <-queries
+	ja := jsonassert.New(c)
+	ja.Assertf(query, `{
+		"product": "Enterprise Frobnicator 9000.1",
+		"component": "Basesystem",
+		"version": "FROB90001Maint-Upd",
+		"summary": "It crashed",
+		"description": "Backtrace follows",
+		"keywords": ["TRETA"],
+		"flags": [{"name": "needinfo", "status": "?", "requestee": "user1@foobarcorp.example.com"}],
+		"cf_foundby": "i18n Test"}`)
+}
+
+func (cs *clientSuite) TestCreateBugError(c *C) {
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"code": 51, "error": true, "message": "There is no component named 'Foo'."}`, http.StatusBadRequest)
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	_, err := bz.CreateBug(bugzilla.NewBug{Product: "Enterprise Frobnicator 9000.1", Component: "Foo"})
+	c.Assert(err, FitsTypeOf, bugzilla.BugzillaError{})
+	c.Assert(err, ErrorMatches, ".*no component named.*")
+}
+
+func (cs *clientSuite) TestGetBugContextCancelled(c *C) {
+	unblock := make(chan struct{})
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer ts0.Close()
+	defer close(unblock)
+	bz := makeClient(ts0.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	bug, err := bz.GetBugContext(ctx, 1047068)
+	c.Assert(bug, IsNil)
+	c.Assert(err, FitsTypeOf, bugzilla.ConnectionError{})
+	c.Assert(err, ErrorMatches, ".*context deadline exceeded.*")
+}
+
+func (cs *clientSuite) TestGetBugs(c *C) {
+	requests := make(chan *http.Request, 10)
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r
+		ids := r.URL.Query()["ids"]
+		docs := []string{}
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/comment"):
+			for _, id := range ids {
+				docs = append(docs, fmt.Sprintf(`"%s": {"comments": [{"id": 1%s, "bug_id": %s, "text": "comment of %s"}]}`, id, id, id, id))
+			}
+			fmt.Fprintf(w, `{"bugs": {%s}}`, strings.Join(docs, ","))
+		case strings.HasSuffix(r.URL.Path, "/attachment"):
+			for _, id := range ids {
+				docs = append(docs, fmt.Sprintf(`"%s": [{"id": 2%s, "bug_id": %s}]`, id, id, id))
+			}
+			fmt.Fprintf(w, `{"bugs": {%s}}`, strings.Join(docs, ","))
+		default:
+			// Reversed, to ensure the order of the IDs passed is kept
+			for i := len(ids) - 1; i >= 0; i-- {
+				docs = append(docs, fmt.Sprintf(`{"id": %s}`, ids[i]))
+			}
+			fmt.Fprintf(w, `{"bugs": [%s]}`, strings.Join(docs, ","))
+		}
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	opts := bugzilla.GetBugsOptions{WithComments: true, WithAttachments: true, ChunkSize: 2}
+	bugs, err := bz.GetBugs([]int{11, 12, 13, 12}, opts)
+	c.Assert(err, IsNil)
+	c.Assert(bugs, HasLen, 3)
+	for i, id := range []int{11, 12, 13} {
+		c.Check(bugs[i].ID, Equals, id)
+		c.Assert(bugs[i].Comments, HasLen, 1)
+		c.Check(bugs[i].Comments[0].Text, Equals, fmt.Sprintf("comment of %d", id))
+		c.Assert(bugs[i].Attachments, HasLen, 1)
+		c.Check(bugs[i].Attachments[0].BugId, Equals, id)
+	}
+
+	// Two chunks, three requests each
+	c.Assert(requests, HasLen, 6)
+	r := <-requests
+	c.Check(r.URL.Query()["ids"], DeepEquals, []string{"11", "12"})
+}
+
+func (cs *clientSuite) TestGetBugsMissing(c *C) {
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/comment"):
+			c.Check(query["ids"], DeepEquals, []string{"11", "13"})
+			io.WriteString(w, `{"bugs": {"11": {"comments": []}, "13": {"comments": []}}}`)
+		default:
+			c.Check(query.Get("permissive"), Equals, "1")
+			io.WriteString(w, `{"bugs": [{"id": 11}, {"id": 13}], "faults": [
+				{"id": 12, "faultCode": 102, "faultString": "You are not authorized to access bug 12."}
+			]}`)
+		}
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	bugs, err := bz.GetBugs([]int{11, 12, 13}, bugzilla.GetBugsOptions{WithComments: true})
+	c.Assert(bugs, HasLen, 2)
+	c.Check(bugs[0].ID, Equals, 11)
+	c.Check(bugs[1].ID, Equals, 13)
+	var missingErr *bugzilla.MissingBugsError
+	c.Assert(errors.As(err, &missingErr), Equals, true)
+	c.Assert(missingErr.Errors, HasLen, 1)
+	c.Check(errors.Is(missingErr.Errors[12], bugzilla.ErrAccessDenied), Equals, true)
+	c.Check(err, ErrorMatches, "Cannot get 1 bug\\(s\\), bug 12: .*not authorized.*")
+}
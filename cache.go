@@ -0,0 +1,334 @@
+package bugzilla
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// CacheReader can be implemented by a Cacher to read back what was written.
+// GetReader returns the contents cached for id and when they were written,
+// or an error when there is nothing cached.
+type CacheReader interface {
+	GetReader(id string) (io.ReadCloser, time.Time, error)
+}
+
+// Kinds of objects other than bugs that can be cached
+const (
+	CacheComments    = "comments"
+	CacheAttachments = "attachments"
+)
+
+// KindCacher can be implemented by a Cacher to also receive the comments
+// and attachment metadata fetched for each bug, with ids in the form
+// "<bug id>/<kind>", as in "1047068/comments". CachesKind tells which of the
+// kinds it wants. When the Cacher is also a CacheReader, GetComments and
+// GetAttachmentsInfo use the entries written less than CacheMaxAge ago.
+type KindCacher interface {
+	CachesKind(kind string) bool
+}
+
+func (c *Client) cachesKind(kind string) bool {
+	kindCacher, ok := c.cacher.(KindCacher)
+	return ok && kindCacher.CachesKind(kind)
+}
+
+func (c *Client) cacheJSON(id string, v interface{}) {
+	b, err := json.Marshal(v)
+	if err == nil {
+		writer := c.cacher.GetWriter(id)
+		writer.Write(b)
+		writer.Close()
+	}
+}
+
+func (c *Client) cacheComments(bugIds []int, comments []Comment) {
+	if !c.cachesKind(CacheComments) {
+		return
+	}
+	byBug := make(map[int][]Comment, len(bugIds))
+	for _, comment := range comments {
+		byBug[comment.BugID] = append(byBug[comment.BugID], comment)
+	}
+	for _, id := range bugIds {
+		bugComments := byBug[id]
+		if bugComments == nil {
+			bugComments = make([]Comment, 0, 0)
+		}
+		c.cacheJSON(fmt.Sprintf("%d/%s", id, CacheComments), bugComments)
+	}
+}
+
+func (c *Client) cacheAttachments(bugIds []int, attachments []Attachment) {
+	if !c.cachesKind(CacheAttachments) {
+		return
+	}
+	byBug := make(map[int][]Attachment, len(bugIds))
+	for _, attachment := range attachments {
+		byBug[attachment.BugId] = append(byBug[attachment.BugId], attachment)
+	}
+	for _, id := range bugIds {
+		bugAttachments := byBug[id]
+		if bugAttachments == nil {
+			bugAttachments = make([]Attachment, 0, 0)
+		}
+		c.cacheJSON(fmt.Sprintf("%d/%s", id, CacheAttachments), bugAttachments)
+	}
+}
+
+// readCachedKind decodes into v the entry of kind cached for the bug id,
+// if it was written less than CacheMaxAge ago
+func (c *Client) readCachedKind(id int, kind string, v interface{}) bool {
+	if c.Config.CacheMaxAge <= 0 || !c.cachesKind(kind) {
+		return false
+	}
+	reader, ok := c.cacher.(CacheReader)
+	if !ok {
+		return false
+	}
+	source, cachedAt, err := reader.GetReader(fmt.Sprintf("%d/%s", id, kind))
+	if err != nil {
+		return false
+	}
+	defer source.Close()
+	if time.Since(cachedAt) >= c.Config.CacheMaxAge {
+		return false
+	}
+	return json.NewDecoder(source).Decode(v) == nil
+}
+
+// cachedComments returns the fresh comments cached for bugIds, by bug, and
+// the bugs that have to be fetched from the server
+func (c *Client) cachedComments(bugIds []int) (map[int][]Comment, []int) {
+	cached := make(map[int][]Comment, len(bugIds))
+	missing := make([]int, 0)
+	seen := make(map[int]bool, len(bugIds))
+	for _, id := range bugIds {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		var comments []Comment
+		if c.readCachedKind(id, CacheComments, &comments) && comments != nil {
+			cached[id] = comments
+			continue
+		}
+		missing = append(missing, id)
+	}
+	return cached, missing
+}
+
+func joinComments(bugIds []int, byBug map[int][]Comment) []Comment {
+	comments := make([]Comment, 0, 0)
+	seen := make(map[int]bool, len(bugIds))
+	for _, id := range bugIds {
+		if !seen[id] {
+			seen[id] = true
+			comments = append(comments, byBug[id]...)
+		}
+	}
+	return comments
+}
+
+// cachedAttachments returns the fresh attachments information cached for
+// bugIds, by bug, and the bugs that have to be fetched from the server
+func (c *Client) cachedAttachments(bugIds []int) (map[int][]Attachment, []int) {
+	cached := make(map[int][]Attachment, len(bugIds))
+	missing := make([]int, 0)
+	seen := make(map[int]bool, len(bugIds))
+	for _, id := range bugIds {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		var attachments []Attachment
+		if c.readCachedKind(id, CacheAttachments, &attachments) && attachments != nil {
+			cached[id] = attachments
+			continue
+		}
+		missing = append(missing, id)
+	}
+	return cached, missing
+}
+
+func joinAttachments(bugIds []int, byBug map[int][]Attachment) []Attachment {
+	attachments := make([]Attachment, 0, 0)
+	seen := make(map[int]bool, len(bugIds))
+	for _, id := range bugIds {
+		if !seen[id] {
+			seen[id] = true
+			attachments = append(attachments, byBug[id]...)
+		}
+	}
+	return attachments
+}
+
+// CacheInvalidator can be implemented by a Cacher to drop entries that
+// became stale after changes done with the Client, such as Update and
+// UploadAttachment. Invalidating an entry that doesn't exist is not an
+// error.
+type CacheInvalidator interface {
+	Invalidate(id string) error
+}
+
+// invalidateBug drops the cached bug and the given kinds of objects cached
+// for it
+func (c *Client) invalidateBug(id int, kinds ...string) {
+	invalidator, ok := c.cacher.(CacheInvalidator)
+	if !ok {
+		return
+	}
+	invalidator.Invalidate(fmt.Sprintf("%d", id))
+	for _, kind := range kinds {
+		if c.cachesKind(kind) {
+			invalidator.Invalidate(fmt.Sprintf("%d/%s", id, kind))
+		}
+	}
+}
+
+func splitChangeList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func patchList(list []string, removed, added string) []string {
+	remove := make(map[string]bool)
+	for _, item := range splitChangeList(removed) {
+		remove[item] = true
+	}
+	patched := make([]string, 0, len(list))
+	for _, item := range list {
+		if !remove[item] {
+			patched = append(patched, item)
+		}
+	}
+	return append(patched, splitChangeList(added)...)
+}
+
+// bugPatchers know how to apply the changes reported in UpdateResponse to
+// each field of a Bug. Fields with others derived from them, as IsOpen from
+// the status or AssignedToDetail from assigned_to, are left out, so that
+// changing them invalidates the cached bug.
+var bugPatchers = map[string]func(bug *Bug, removed, added string){
+	"component":        func(b *Bug, _, v string) { b.Component = v },
+	"op_sys":           func(b *Bug, _, v string) { b.OpSys = v },
+	"platform":         func(b *Bug, _, v string) { b.Platform = v },
+	"priority":         func(b *Bug, _, v string) { b.Priority = v },
+	"product":          func(b *Bug, _, v string) { b.Product = v },
+	"resolution":       func(b *Bug, _, v string) { b.Resolution = v },
+	"severity":         func(b *Bug, _, v string) { b.Severity = v },
+	"summary":          func(b *Bug, _, v string) { b.Summary = v },
+	"target_milestone": func(b *Bug, _, v string) { b.TargetMilestone = v },
+	"url":              func(b *Bug, _, v string) { b.URL = v },
+	"version":          func(b *Bug, _, v string) { b.Version = v },
+	"whiteboard":       func(b *Bug, _, v string) { b.Whiteboard = v },
+	"keywords":         func(b *Bug, r, a string) { b.Keywords = patchList(b.Keywords, r, a) },
+}
+
+// updateCachedBug applies the changes reported by Bugzilla to the cached
+// copy of the bug. When that is not possible, as in the case of new
+// comments or flag changes, the cached copy is invalidated instead.
+func (c *Client) updateCachedBug(resp *UpdateResponse, commented bool) {
+	if commented {
+		c.invalidateBug(resp.Id, CacheComments)
+		return
+	}
+	bug, _, ok := c.readCachedBug(resp.Id)
+	if !ok {
+		c.invalidateBug(resp.Id)
+		return
+	}
+	for field, change := range resp.Changes {
+		patch, ok := bugPatchers[field]
+		if !ok {
+			c.invalidateBug(resp.Id)
+			return
+		}
+		patch(bug, change.Removed, change.Added)
+	}
+	bug.LastChangeTime = resp.LastChangeTime
+	// The token is tied to the last change time, so it can't be reused
+	bug.UpdateToken = ""
+	c.cacheBugs([]Bug{*bug})
+}
+
+type lastChange struct {
+	ID             int       `json:"id"`
+	LastChangeTime time.Time `json:"last_change_time"`
+}
+
+type lastChangeResult struct {
+	Bugs []lastChange `json:"bugs"`
+}
+
+func (c *Client) readCachedBug(id int) (*Bug, time.Time, bool) {
+	reader, ok := c.cacher.(CacheReader)
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	source, cachedAt, err := reader.GetReader(fmt.Sprintf("%d", id))
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	defer source.Close()
+	bug, err := c.GetBugFromJSON(source)
+	if err != nil || bug.ID != id {
+		return nil, time.Time{}, false
+	}
+	return bug, cachedAt, true
+}
+
+// lastChangeTime fetches only the last change time of a bug
+func (c *Client) lastChangeTime(ctx context.Context, id int) (time.Time, error) {
+	params := map[string]string{"include_fields": "id,last_change_time"}
+	url, err := c.getBugsURL([]int{id}, params)
+	if err != nil {
+		return time.Time{}, err
+	}
+	body, err := c.fetch(ctx, url)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var result lastChangeResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return time.Time{}, DecodeErrror{err}
+	}
+	if len(result.Bugs) != 1 {
+		return time.Time{}, DecodeErrror{fmt.Errorf("unexpected number of bugs returned: %v", len(result.Bugs))}
+	}
+	return result.Bugs[0].LastChangeTime, nil
+}
+
+// cachedBug returns the cached copy of a bug if it is recent enough
+// according to CacheMaxAge or, with CacheRevalidate, if it has not changed
+// on the server. Revalidated bugs are written again to the cache to be
+// considered fresh.
+func (c *Client) cachedBug(ctx context.Context, id int) (*Bug, bool) {
+	if c.Config.CacheMaxAge <= 0 && !c.Config.CacheRevalidate {
+		return nil, false
+	}
+	bug, cachedAt, ok := c.readCachedBug(id)
+	if !ok {
+		return nil, false
+	}
+	if time.Since(cachedAt) < c.Config.CacheMaxAge {
+		return bug, true
+	}
+	if !c.Config.CacheRevalidate {
+		return nil, false
+	}
+	changed, err := c.lastChangeTime(ctx, id)
+	if err != nil || !changed.Equal(bug.LastChangeTime) {
+		return nil, false
+	}
+	c.cacheBugs([]Bug{*bug})
+	return bug, true
+}
@@ -0,0 +1,255 @@
+package bugzilla_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"time"
+
+	bugzilla "github.com/bhdn/go-bugzilla-rest"
+	. "gopkg.in/check.v1"
+)
+
+// MemCacher keeps the cached contents in memory and allows faking their
+// age
+type MemCacher struct {
+	mu       sync.Mutex
+	contents map[string][]byte
+	cachedAt map[string]time.Time
+}
+
+func NewMemCacher() *MemCacher {
+	return &MemCacher{contents: map[string][]byte{}, cachedAt: map[string]time.Time{}}
+}
+
+type memWriter struct {
+	bytes.Buffer
+	id     string
+	cacher *MemCacher
+}
+
+func (w *memWriter) Close() error {
+	w.cacher.mu.Lock()
+	defer w.cacher.mu.Unlock()
+	w.cacher.contents[w.id] = w.Bytes()
+	w.cacher.cachedAt[w.id] = time.Now()
+	return nil
+}
+
+func (m *MemCacher) GetWriter(id string) io.WriteCloser {
+	return &memWriter{id: id, cacher: m}
+}
+
+func (m *MemCacher) GetReader(id string) (io.ReadCloser, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	contents, ok := m.contents[id]
+	if !ok {
+		return nil, time.Time{}, errors.New("not cached")
+	}
+	return ioutil.NopCloser(bytes.NewReader(contents)), m.cachedAt[id], nil
+}
+
+func (m *MemCacher) age(id string, by time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cachedAt[id] = m.cachedAt[id].Add(-by)
+}
+
+func makeClientWithCacheConfig(url string, cacher bugzilla.Cacher, maxAge time.Duration, revalidate bool) *bugzilla.Client {
+	config := bugzilla.Config{
+		BaseURL:         url,
+		ApiKey:          "xxxxxx",
+		Cacher:          cacher,
+		CacheMaxAge:     maxAge,
+		CacheRevalidate: revalidate,
+	}
+	bz, _ := bugzilla.New(config)
+	return bz
+}
+
+// makeCountingServer wraps the REST server counting the requests by path
+func (cs *clientSuite) makeCountingServer(c *C, lastChange *string) (*httptest.Server, map[string]int) {
+	var mu sync.Mutex
+	counts := map[string]int{}
+	rest := cs.makeBugzillaRestServer(c, 1047068)
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		counts[r.URL.Path]++
+		mu.Unlock()
+		if r.URL.Query().Get("include_fields") == "id,last_change_time" {
+			io.WriteString(w, `{"bugs": [{"id": 1047068, "last_change_time": "`+*lastChange+`"}]}`)
+			return
+		}
+		r.URL.Scheme = "http"
+		r.URL.Host = rest.Listener.Addr().String()
+		resp, err := http.Get(r.URL.String())
+		c.Assert(err, IsNil)
+		defer resp.Body.Close()
+		io.Copy(w, resp.Body)
+	}))
+	return ts0, counts
+}
+
+func (cs *clientSuite) TestGetBugFromCache(c *C) {
+	lastChange := "2023-04-12T01:02:03Z"
+	ts0, counts := cs.makeCountingServer(c, &lastChange)
+	defer ts0.Close()
+	cacher := NewMemCacher()
+	bz := makeClientWithCacheConfig(ts0.URL, cacher, time.Hour, false)
+
+	bug, err := bz.GetBug(1047068)
+	c.Assert(err, IsNil)
+	c.Check(counts["/rest/bug/1047068"], Equals, 1)
+
+	// Fresh, so no new requests
+	cached, err := bz.GetBug(1047068)
+	c.Assert(err, IsNil)
+	c.Check(counts["/rest/bug/1047068"], Equals, 1)
+	c.Check(cached.Summary, Equals, bug.Summary)
+	c.Check(cached.Comments, HasLen, 4)
+	c.Check(cached.Attachments, HasLen, 11)
+
+	cached, err = bz.GetBugEx(1047068, false, false)
+	c.Assert(err, IsNil)
+	c.Check(counts["/rest/bug/1047068"], Equals, 1)
+	c.Check(cached.Comments, HasLen, 0)
+	c.Check(cached.Attachments, HasLen, 0)
+
+	// Stale, so it is fetched again
+	cacher.age("1047068", 2*time.Hour)
+	_, err = bz.GetBug(1047068)
+	c.Assert(err, IsNil)
+	c.Check(counts["/rest/bug/1047068"], Equals, 2)
+}
+
+func (cs *clientSuite) TestGetBugCacheRevalidate(c *C) {
+	lastChange := "2023-04-12T01:02:03Z"
+	ts0, counts := cs.makeCountingServer(c, &lastChange)
+	defer ts0.Close()
+	cacher := NewMemCacher()
+	bz := makeClientWithCacheConfig(ts0.URL, cacher, time.Hour, true)
+
+	_, err := bz.GetBug(1047068)
+	c.Assert(err, IsNil)
+	c.Check(counts["/rest/bug/1047068"], Equals, 1)
+
+	// Stale but unchanged, only the cheap request is made
+	cacher.age("1047068", 2*time.Hour)
+	_, err = bz.GetBug(1047068)
+	c.Assert(err, IsNil)
+	c.Check(counts["/rest/bug/1047068"], Equals, 2)
+	c.Check(counts["/rest/bug/1047068/comment"], Equals, 1)
+
+	// And it is considered fresh again
+	_, err = bz.GetBug(1047068)
+	c.Assert(err, IsNil)
+	c.Check(counts["/rest/bug/1047068"], Equals, 2)
+
+	// Changed on the server, fetched again completely
+	cacher.age("1047068", 2*time.Hour)
+	lastChange = "2023-05-01T00:00:00Z"
+	_, err = bz.GetBug(1047068)
+	c.Assert(err, IsNil)
+	c.Check(counts["/rest/bug/1047068"], Equals, 4)
+	c.Check(counts["/rest/bug/1047068/comment"], Equals, 2)
+}
+
+func (cs *clientSuite) TestPartialBugsNotCached(c *C) {
+	ts0 := cs.makeBugzillaRestServer(c, 1047068)
+	defer ts0.Close()
+	var cacher CacherHelper
+	bz := makeClientWithCache(ts0.URL, &cacher)
+
+	_, err := bz.GetBugEx(1047068, false, false)
+	c.Assert(err, IsNil)
+	c.Check(cacher.id, Equals, "")
+}
+
+func (cs *clientSuite) TestUpdatePatchesCache(c *C) {
+	ts0, queries, nextJson, processBug := makeBugzillaServerWithChannels()
+	defer ts0.Close()
+	cacher, err := bugzilla.NewFileCacher(c.MkDir(), bugzilla.FileCacherOptions{})
+	c.Assert(err, IsNil)
+	bz := makeClientWithCacheConfig(ts0.URL, cacher, time.Hour, false)
+
+	nextJson <- bugsJson
+	nextJson <- bugsCommentsJson
+	nextJson <- bugsAttachmentsJson
+	_, err = bz.GetBug(1047068)
+	c.Assert(err, IsNil)
+
+	nextJson <- bugsJson
+	processBug <- `{"bugs": [{"alias": [], "id": 1047068,
+		"last_change_time": "2023-05-09T09:30:30Z",
+		"changes": {
+			"whiteboard": {"added": "openTreta", "removed": ""},
+			"keywords": {"added": "FIXED_UPSTREAM", "removed": "TRETA"}
+		}}]}`
+	_, err = bz.Update(1047068, bugzilla.Changes{SetWhiteboard: "openTreta"})
+	c.Assert(err, IsNil)
+	<-queries
+
+	bug, err := bz.GetBug(1047068)
+	c.Assert(err, IsNil)
+	c.Check(bug.Whiteboard, Equals, "openTreta")
+	c.Check(bug.Keywords, DeepEquals, []string{"TRETA_ADDRESSED", "FIXED_UPSTREAM"})
+	c.Check(bug.LastChangeTime, Equals, time.Date(2023, 5, 9, 9, 30, 30, 0, time.UTC))
+	c.Check(bug.UpdateToken, Equals, "")
+	c.Check(bug.Comments, HasLen, 4)
+
+	// The open state depends on the workflow of the installation, so a
+	// status change drops the bug from the cache
+	nextJson <- bugsJson
+	processBug <- `{"bugs": [{"alias": [], "id": 1047068,
+		"last_change_time": "2023-05-09T09:35:30Z",
+		"changes": {"status": {"added": "RESOLVED", "removed": "REOPENED"}}}]}`
+	_, err = bz.Update(1047068, bugzilla.Changes{SetStatus: "RESOLVED", SetResolution: "FIXED"})
+	c.Assert(err, IsNil)
+	<-queries
+	_, _, err = cacher.GetReader("1047068")
+	c.Check(os.IsNotExist(err), Equals, true)
+
+	// Comments can't be patched in, so the bug is dropped from the cache
+	nextJson <- bugsJson
+	processBug <- `{"bugs": [{"alias": [], "id": 1047068, "changes": {},
+		"last_change_time": "2023-05-09T09:40:30Z"}]}`
+	_, err = bz.Update(1047068, bugzilla.Changes{AddComment: "Fixed"})
+	c.Assert(err, IsNil)
+	<-queries
+	_, _, err = cacher.GetReader("1047068")
+	c.Check(os.IsNotExist(err), Equals, true)
+	_, _, err = cacher.GetReader("1047068/comments")
+	c.Check(os.IsNotExist(err), Equals, true)
+	_, _, err = cacher.GetReader("1047068/attachments")
+	c.Check(err, IsNil)
+}
+
+func (cs *clientSuite) TestUploadAttachmentInvalidatesCache(c *C) {
+	ts0, queries, nextJson, processBug := makeBugzillaServerWithChannels()
+	defer ts0.Close()
+	cacher, err := bugzilla.NewFileCacher(c.MkDir(), bugzilla.FileCacherOptions{})
+	c.Assert(err, IsNil)
+	bz := makeClientWithCacheConfig(ts0.URL, cacher, time.Hour, false)
+
+	nextJson <- bugsJson
+	nextJson <- bugsCommentsJson
+	nextJson <- bugsAttachmentsJson
+	_, err = bz.GetBug(1047068)
+	c.Assert(err, IsNil)
+
+	processBug <- `{"ids":[866923]}`
+	_, err = bz.UploadAttachment(1047068, &bugzilla.PostAttachment{Data: []byte("a\n")})
+	c.Assert(err, IsNil)
+	<-queries
+
+	for _, id := range []string{"1047068", "1047068/comments", "1047068/attachments"} {
+		_, _, err = cacher.GetReader(id)
+		c.Check(os.IsNotExist(err), Equals, true)
+	}
+}
@@ -0,0 +1,187 @@
+package bugzilla
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// AddCommentOptions are the optional settings of a comment added with
+// AddComment
+type AddCommentOptions struct {
+	IsPrivate bool
+	// IsMarkdown requires Bugzilla 5.2 or newer
+	IsMarkdown bool
+	// WorkTime is added to the hours worked on the bug
+	WorkTime float64
+	// Tags are comment tags, as in "spam" or "workaround"
+	Tags []string
+}
+
+type newComment struct {
+	Comment    string   `json:"comment"`
+	IsPrivate  bool     `json:"is_private,omitempty"`
+	IsMarkdown bool     `json:"is_markdown,omitempty"`
+	WorkTime   float64  `json:"work_time,omitempty"`
+	Tags       []string `json:"comment_tags,omitempty"`
+}
+
+type addCommentResponse struct {
+	ID int `json:"id"`
+}
+
+func (c *Client) getAddCommentURL(bugID int) (string, error) {
+	return c.makeURL(fmt.Sprintf("/rest/bug/%d/comment", bugID), &url.Values{})
+}
+
+func (c *Client) encodeComment(comment *newComment) ([]byte, error) {
+	b, err := json.Marshal(comment)
+	if err != nil {
+		return nil, RequestError{fmt.Errorf("Cannot build comment: %v", err)}
+	}
+	return b, nil
+}
+
+func (c *Client) decodeAddComment(data []byte) (int, error) {
+	var result addCommentResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return 0, DecodeErrror{err}
+	}
+	if result.ID == 0 {
+		return 0, DecodeErrror{fmt.Errorf("no comment ID in the response")}
+	}
+	return result.ID, nil
+}
+
+// AddComment adds a comment to the bug bugID, returning the ID of the new
+// comment. Unlike Update with Changes.AddComment, it takes a single request.
+func (c *Client) AddComment(bugID int, text string, opts AddCommentOptions) (int, error) {
+	return c.AddCommentContext(context.Background(), bugID, text, opts)
+}
+
+// AddCommentContext is AddComment with a context.Context
+func (c *Client) AddCommentContext(ctx context.Context, bugID int, text string, opts AddCommentOptions) (int, error) {
+	if text == "" {
+		return 0, RequestError{fmt.Errorf("empty comment")}
+	}
+	url, err := c.getAddCommentURL(bugID)
+	if err != nil {
+		return 0, err
+	}
+	encoded, err := c.encodeComment(&newComment{
+		Comment:    text,
+		IsPrivate:  opts.IsPrivate,
+		IsMarkdown: opts.IsMarkdown,
+		WorkTime:   opts.WorkTime,
+		Tags:       opts.Tags,
+	})
+	if err != nil {
+		return 0, err
+	}
+	resp, err := c.post(ctx, url, "application/json", encoded)
+	if err != nil {
+		return 0, err
+	}
+	id, err := c.decodeAddComment(resp)
+	if err != nil {
+		return 0, err
+	}
+	c.invalidateBug(bugID, CacheComments)
+	return id, nil
+}
+
+type commentTagsUpdate struct {
+	CommentID int      `json:"comment_id"`
+	Add       []string `json:"add,omitempty"`
+	Remove    []string `json:"remove,omitempty"`
+}
+
+type commentResult struct {
+	Comments map[string]Comment `json:"comments"`
+}
+
+func (c *Client) getCommentTagsURL(commentID int) (string, error) {
+	return c.makeURL(fmt.Sprintf("/rest/bug/comment/%d/tags", commentID), &url.Values{})
+}
+
+func (c *Client) getSearchCommentTagsURL(query string) (string, error) {
+	return c.makeSegmentsURL("/rest/bug/comment/tags", []string{query}, &url.Values{})
+}
+
+func (c *Client) decodeCommentTags(data []byte) ([]string, error) {
+	tags := make([]string, 0)
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return nil, DecodeErrror{err}
+	}
+	return tags, nil
+}
+
+// UpdateCommentTags adds and removes tags of the comment commentID,
+// returning all the tags the comment has after the change
+func (c *Client) UpdateCommentTags(commentID int, add []string, remove []string) ([]string, error) {
+	return c.UpdateCommentTagsContext(context.Background(), commentID, add, remove)
+}
+
+// UpdateCommentTagsContext is UpdateCommentTags with a context.Context
+func (c *Client) UpdateCommentTagsContext(ctx context.Context, commentID int, add []string, remove []string) ([]string, error) {
+	url, err := c.getCommentTagsURL(commentID)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(&commentTagsUpdate{CommentID: commentID, Add: add, Remove: remove})
+	if err != nil {
+		return nil, RequestError{fmt.Errorf("Cannot build tags update: %v", err)}
+	}
+	resp, err := c.put(ctx, url, "application/json", encoded)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := c.decodeCommentTags(resp)
+	if err != nil {
+		return nil, err
+	}
+	c.invalidateComment(ctx, commentID)
+	return tags, nil
+}
+
+// invalidateComment drops the cached comments of the bug of the comment
+// id, which has to be looked up
+func (c *Client) invalidateComment(ctx context.Context, id int) {
+	if _, ok := c.cacher.(CacheInvalidator); !ok {
+		return
+	}
+	url, err := c.makeURL(fmt.Sprintf("/rest/bug/comment/%d", id), c.valuesFromMap(map[string]string{"include_fields": "bug_id"}))
+	if err != nil {
+		return
+	}
+	body, err := c.fetch(ctx, url)
+	if err != nil {
+		return
+	}
+	var result commentResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return
+	}
+	if comment, ok := result.Comments[fmt.Sprintf("%d", id)]; ok {
+		c.invalidateBug(comment.BugID, CacheComments)
+	}
+}
+
+// SearchCommentTags returns the comment tags in use that contain query
+func (c *Client) SearchCommentTags(query string) ([]string, error) {
+	return c.SearchCommentTagsContext(context.Background(), query)
+}
+
+// SearchCommentTagsContext is SearchCommentTags with a context.Context
+func (c *Client) SearchCommentTagsContext(ctx context.Context, query string) ([]string, error) {
+	url, err := c.getSearchCommentTagsURL(query)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return c.decodeCommentTags(body)
+}
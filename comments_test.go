@@ -0,0 +1,176 @@
+package bugzilla_test
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"time"
+
+	bugzilla "github.com/bhdn/go-bugzilla-rest"
+	"github.com/kinbiko/jsonassert"
+	. "gopkg.in/check.v1"
+)
+
+func (cs *clientSuite) TestAddComment(c *C) {
+	ts0, queries, _, processBug := makeBugzillaServerWithChannels()
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+	ja := jsonassert.New(c)
+
+	processBug <- `{"id": 789}`
+	id, err := bz.AddComment(1047068, "Still happening", bugzilla.AddCommentOptions{})
+	c.Assert(err, IsNil)
+	c.Check(id, Equals, 789)
+	ja.Assertf(<-queries, `{"comment": "Still happening"}`)
+
+	processBug <- `{"id": 790}`
+	opts := bugzilla.AddCommentOptions{
+		IsPrivate:  true,
+		IsMarkdown: true,
+		WorkTime:   1.5,
+		Tags:       []string{"workaround"},
+	}
+	id, err = bz.AddComment(1047068, "Use `--force`", opts)
+	c.Assert(err, IsNil)
+	c.Check(id, Equals, 790)
+	ja.Assertf(<-queries, `{
+		"comment": "Use `+"`--force`"+`",
+		"is_private": true,
+		"is_markdown": true,
+		"work_time": 1.5,
+		"comment_tags": ["workaround"]
+	}`)
+
+	_, err = bz.AddComment(1047068, "", bugzilla.AddCommentOptions{})
+	c.Check(err, ErrorMatches, ".*empty comment.*")
+}
+
+func (cs *clientSuite) TestAddCommentInvalidatesCache(c *C) {
+	ts0, queries, nextJson, processBug := makeBugzillaServerWithChannels()
+	defer ts0.Close()
+	cacher, err := bugzilla.NewFileCacher(c.MkDir(), bugzilla.FileCacherOptions{})
+	c.Assert(err, IsNil)
+	bz := makeClientWithCacheConfig(ts0.URL, cacher, time.Hour, false)
+
+	nextJson <- bugsJson
+	nextJson <- bugsCommentsJson
+	nextJson <- bugsAttachmentsJson
+	_, err = bz.GetBug(1047068)
+	c.Assert(err, IsNil)
+
+	processBug <- `{"id": 789}`
+	_, err = bz.AddComment(1047068, "Still happening", bugzilla.AddCommentOptions{})
+	c.Assert(err, IsNil)
+	<-queries
+
+	for _, id := range []string{"1047068", "1047068/comments"} {
+		_, _, err = cacher.GetReader(id)
+		c.Check(os.IsNotExist(err), Equals, true)
+	}
+	_, _, err = cacher.GetReader("1047068/attachments")
+	c.Check(err, IsNil)
+}
+
+func (cs *clientSuite) TestAddCommentPath(c *C) {
+	paths := make(chan string, 1)
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths <- r.Method + " " + r.URL.Path
+		io.WriteString(w, `{"id": 789}`)
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	_, err := bz.AddComment(1047068, "Still happening", bugzilla.AddCommentOptions{})
+	c.Assert(err, IsNil)
+	c.Check(<-paths, Equals, "POST /rest/bug/1047068/comment")
+}
+
+func (cs *clientSuite) TestCommentTags(c *C) {
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"bugs": {"1047068": {"comments": [
+			{"id": 1, "bug_id": 1047068, "count": 0, "text": "Crash", "tags": []},
+			{"id": 2, "bug_id": 1047068, "count": 1, "text": "Buy now", "tags": ["spam"]}
+		]}}, "comments": {}}`)
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	comments, err := bz.GetComments([]int{1047068})
+	c.Assert(err, IsNil)
+	c.Assert(comments, HasLen, 2)
+	c.Check(comments[0].Tags, HasLen, 0)
+	c.Check(comments[1].Tags, DeepEquals, []string{"spam"})
+}
+
+func (cs *clientSuite) TestUpdateCommentTags(c *C) {
+	requests := make(chan string, 1)
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests <- r.Method + " " + r.URL.Path + " " + string(body)
+		io.WriteString(w, `["spam", "workaround"]`)
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	tags, err := bz.UpdateCommentTags(2, []string{"workaround"}, []string{"obsolete"})
+	c.Assert(err, IsNil)
+	c.Check(tags, DeepEquals, []string{"spam", "workaround"})
+	request := strings.SplitN(<-requests, " ", 3)
+	c.Check(request[0], Equals, "PUT")
+	c.Check(request[1], Equals, "/rest/bug/comment/2/tags")
+	ja := jsonassert.New(c)
+	ja.Assertf(request[2], `{"comment_id": 2, "add": ["workaround"], "remove": ["obsolete"]}`)
+}
+
+func (cs *clientSuite) TestUpdateCommentTagsInvalidatesCache(c *C) {
+	ts0, queries, nextJson, processBug := makeBugzillaServerWithChannels()
+	defer ts0.Close()
+	cacher, err := bugzilla.NewFileCacher(c.MkDir(), bugzilla.FileCacherOptions{})
+	c.Assert(err, IsNil)
+	bz := makeClientWithCacheConfig(ts0.URL, cacher, time.Hour, false)
+
+	nextJson <- bugsJson
+	nextJson <- bugsCommentsJson
+	nextJson <- bugsAttachmentsJson
+	_, err = bz.GetBug(1047068)
+	c.Assert(err, IsNil)
+
+	processBug <- `["spam"]`
+	nextJson <- `{"comments": {"2": {"bug_id": 1047068}}, "bugs": {}}`
+	_, err = bz.UpdateCommentTags(2, []string{"spam"}, nil)
+	c.Assert(err, IsNil)
+	<-queries
+
+	for _, id := range []string{"1047068", "1047068/comments"} {
+		_, _, err = cacher.GetReader(id)
+		c.Check(os.IsNotExist(err), Equals, true)
+	}
+}
+
+func (cs *clientSuite) TestSearchCommentTags(c *C) {
+	paths := make(chan string, 1)
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths <- r.Method + " " + r.URL.EscapedPath()
+		io.WriteString(w, `["workaround", "workaround-tested"]`)
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	tags, err := bz.SearchCommentTags("work")
+	c.Assert(err, IsNil)
+	c.Check(<-paths, Equals, "GET /rest/bug/comment/tags/work")
+	c.Check(tags, DeepEquals, []string{"workaround", "workaround-tested"})
+
+	// The query can't point to another endpoint
+	for query, path := range map[string]string{
+		"..":       "/rest/bug/comment/tags/%2E%2E",
+		"../1/tag": "/rest/bug/comment/tags/..%2F1%2Ftag",
+	} {
+		_, err = bz.SearchCommentTags(query)
+		c.Assert(err, IsNil)
+		c.Check(<-paths, Equals, "GET "+path)
+	}
+}
@@ -0,0 +1,230 @@
+package bugzilla
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldDiff is the change of a scalar field
+type FieldDiff struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// ListDiff is the change of a list field, such as cc or keywords
+type ListDiff struct {
+	Field   string   `json:"field"`
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// FlagDiff is the change of a flag. Old is nil for new flags and New is nil
+// for removed flags.
+type FlagDiff struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Old  *Flag  `json:"old,omitempty"`
+	New  *Flag  `json:"new,omitempty"`
+}
+
+// BugDiff has the differences between two snapshots of a bug, as returned
+// by Diff. It can be encoded as JSON or rendered as text with String().
+type BugDiff struct {
+	ID                   int          `json:"id"`
+	Fields               []FieldDiff  `json:"fields,omitempty"`
+	Lists                []ListDiff   `json:"lists,omitempty"`
+	Flags                []FlagDiff   `json:"flags,omitempty"`
+	NewComments          []Comment    `json:"new_comments,omitempty"`
+	NewAttachments       []Attachment `json:"new_attachments,omitempty"`
+	ObsoletedAttachments []Attachment `json:"obsoleted_attachments,omitempty"`
+}
+
+// diffFields are the scalar fields compared by Diff
+var diffFields = []struct {
+	name  string
+	value func(b *Bug) string
+}{
+	{"product", func(b *Bug) string { return b.Product }},
+	{"component", func(b *Bug) string { return b.Component }},
+	{"version", func(b *Bug) string { return b.Version }},
+	{"summary", func(b *Bug) string { return b.Summary }},
+	{"status", func(b *Bug) string { return b.Status }},
+	{"resolution", func(b *Bug) string { return b.Resolution }},
+	{"dupe_of", func(b *Bug) string {
+		if b.DupeOf == nil {
+			return ""
+		}
+		return fmt.Sprintf("%d", *b.DupeOf)
+	}},
+	{"priority", func(b *Bug) string { return b.Priority }},
+	{"severity", func(b *Bug) string { return b.Severity }},
+	{"assigned_to", func(b *Bug) string { return b.AssignedTo }},
+	{"qa_contact", func(b *Bug) string { return b.QAContact }},
+	{"target_milestone", func(b *Bug) string { return b.TargetMilestone }},
+	{"whiteboard", func(b *Bug) string { return b.Whiteboard }},
+	{"url", func(b *Bug) string { return b.URL }},
+	{"op_sys", func(b *Bug) string { return b.OpSys }},
+	{"platform", func(b *Bug) string { return b.Platform }},
+	{"deadline", func(b *Bug) string { return b.Deadline }},
+}
+
+func intsToStrings(ints []int) []string {
+	strs := make([]string, 0, len(ints))
+	for _, i := range ints {
+		strs = append(strs, fmt.Sprintf("%d", i))
+	}
+	return strs
+}
+
+// diffLists are the list fields compared by Diff
+var diffLists = []struct {
+	name  string
+	value func(b *Bug) []string
+}{
+	{"alias", func(b *Bug) []string { return b.Alias }},
+	{"cc", func(b *Bug) []string { return b.CC }},
+	{"keywords", func(b *Bug) []string { return b.Keywords }},
+	{"blocks", func(b *Bug) []string { return intsToStrings(b.Blocks) }},
+	{"depends_on", func(b *Bug) []string { return intsToStrings(b.DependsOn) }},
+	{"see_also", func(b *Bug) []string { return b.SeeAlso }},
+	{"groups", func(b *Bug) []string { return b.Groups }},
+}
+
+// listChanges returns the items only in new and the items only in old
+func listChanges(old, new []string) (added []string, removed []string) {
+	inOld := make(map[string]bool, len(old))
+	for _, item := range old {
+		inOld[item] = true
+	}
+	inNew := make(map[string]bool, len(new))
+	for _, item := range new {
+		inNew[item] = true
+		if !inOld[item] {
+			added = append(added, item)
+		}
+	}
+	for _, item := range old {
+		if !inNew[item] {
+			removed = append(removed, item)
+		}
+	}
+	return
+}
+
+// Diff compares two snapshots of the same bug. Comments are considered new
+// by their count and attachments by their ID, so both snapshots should
+// have been fetched with comments and attachments for these to be
+// meaningful.
+func Diff(old, new *Bug) *BugDiff {
+	diff := &BugDiff{ID: new.ID}
+	for _, field := range diffFields {
+		if o, n := field.value(old), field.value(new); o != n {
+			diff.Fields = append(diff.Fields, FieldDiff{Field: field.name, Old: o, New: n})
+		}
+	}
+	for _, list := range diffLists {
+		added, removed := listChanges(list.value(old), list.value(new))
+		if len(added) > 0 || len(removed) > 0 {
+			diff.Lists = append(diff.Lists, ListDiff{Field: list.name, Added: added, Removed: removed})
+		}
+	}
+
+	oldFlags := make(map[int]Flag, len(old.Flags))
+	for _, flag := range old.Flags {
+		oldFlags[flag.ID] = flag
+	}
+	newFlags := make(map[int]bool, len(new.Flags))
+	for i := range new.Flags {
+		flag := &new.Flags[i]
+		newFlags[flag.ID] = true
+		oldFlag, existed := oldFlags[flag.ID]
+		switch {
+		case !existed:
+			diff.Flags = append(diff.Flags, FlagDiff{ID: flag.ID, Name: flag.Name, New: flag})
+		case oldFlag.Status != flag.Status || oldFlag.Requestee != flag.Requestee:
+			diff.Flags = append(diff.Flags, FlagDiff{ID: flag.ID, Name: flag.Name, Old: &oldFlag, New: flag})
+		}
+	}
+	for i := range old.Flags {
+		if flag := &old.Flags[i]; !newFlags[flag.ID] {
+			diff.Flags = append(diff.Flags, FlagDiff{ID: flag.ID, Name: flag.Name, Old: flag})
+		}
+	}
+
+	lastCount := -1
+	for _, comment := range old.Comments {
+		if comment.Count > lastCount {
+			lastCount = comment.Count
+		}
+	}
+	for _, comment := range new.Comments {
+		if comment.Count > lastCount {
+			diff.NewComments = append(diff.NewComments, comment)
+		}
+	}
+
+	oldAttachments := make(map[int]Attachment, len(old.Attachments))
+	for _, attachment := range old.Attachments {
+		oldAttachments[attachment.ID] = attachment
+	}
+	for _, attachment := range new.Attachments {
+		oldAttachment, existed := oldAttachments[attachment.ID]
+		if !existed {
+			diff.NewAttachments = append(diff.NewAttachments, attachment)
+		} else if oldAttachment.IsObsolete == 0 && attachment.IsObsolete != 0 {
+			diff.ObsoletedAttachments = append(diff.ObsoletedAttachments, attachment)
+		}
+	}
+
+	return diff
+}
+
+// Empty tells whether no differences were found
+func (d *BugDiff) Empty() bool {
+	return len(d.Fields) == 0 && len(d.Lists) == 0 && len(d.Flags) == 0 &&
+		len(d.NewComments) == 0 && len(d.NewAttachments) == 0 &&
+		len(d.ObsoletedAttachments) == 0
+}
+
+func flagString(name string, flag *Flag) string {
+	if flag == nil {
+		return "(none)"
+	}
+	if flag.Requestee != "" {
+		return fmt.Sprintf("%s%s(%s)", name, flag.Status, flag.Requestee)
+	}
+	return name + flag.Status
+}
+
+// String renders the differences as text, one per line
+func (d *BugDiff) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Bug %d:\n", d.ID)
+	for _, field := range d.Fields {
+		fmt.Fprintf(&b, "  %s: %q -> %q\n", field.Field, field.Old, field.New)
+	}
+	for _, list := range d.Lists {
+		changes := make([]string, 0, len(list.Added)+len(list.Removed))
+		for _, item := range list.Added {
+			changes = append(changes, "+"+item)
+		}
+		for _, item := range list.Removed {
+			changes = append(changes, "-"+item)
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", list.Field, strings.Join(changes, " "))
+	}
+	for _, flag := range d.Flags {
+		fmt.Fprintf(&b, "  flag %d: %s -> %s\n", flag.ID, flagString(flag.Name, flag.Old), flagString(flag.Name, flag.New))
+	}
+	for _, comment := range d.NewComments {
+		fmt.Fprintf(&b, "  new comment #%d by %s\n", comment.Count, comment.Creator)
+	}
+	for _, attachment := range d.NewAttachments {
+		fmt.Fprintf(&b, "  new attachment %d: %s\n", attachment.ID, attachment.Summary)
+	}
+	for _, attachment := range d.ObsoletedAttachments {
+		fmt.Fprintf(&b, "  obsoleted attachment %d: %s\n", attachment.ID, attachment.Summary)
+	}
+	return b.String()
+}
@@ -0,0 +1,100 @@
+package bugzilla_test
+
+import (
+	"encoding/json"
+	"strings"
+
+	bugzilla "github.com/bhdn/go-bugzilla-rest"
+	"github.com/kinbiko/jsonassert"
+	. "gopkg.in/check.v1"
+)
+
+func (cs *clientSuite) sampleSnapshots(c *C) (*bugzilla.Bug, *bugzilla.Bug) {
+	bz := makeClient("http://bz.foobarcorp.example.com")
+	old, err := bz.GetBugFromJSON(strings.NewReader(sampleJSON))
+	c.Assert(err, IsNil)
+	new, err := bz.GetBugFromJSON(strings.NewReader(sampleJSON))
+	c.Assert(err, IsNil)
+	return old, new
+}
+
+func (cs *clientSuite) TestDiffNoChanges(c *C) {
+	old, new := cs.sampleSnapshots(c)
+	diff := bugzilla.Diff(old, new)
+	c.Check(diff.Empty(), Equals, true)
+	c.Check(diff.String(), Equals, "Bug 1047068:\n")
+}
+
+func (cs *clientSuite) TestDiff(c *C) {
+	old, new := cs.sampleSnapshots(c)
+	old.Attachments = []bugzilla.Attachment{{ID: 1, Summary: "log"}}
+
+	new.Status = "RESOLVED"
+	new.Resolution = "FIXED"
+	new.Keywords = []string{"TRETA", "FIXED_UPSTREAM"}
+	new.Blocks = append(new.Blocks, 1000)
+	new.Flags = new.Flags[1:]
+	new.Flags[0].Requestee = "user2@foobarcorp.example.com"
+	new.Flags = append(new.Flags, bugzilla.Flag{ID: 300000, Name: "needinfo", Status: "?", Requestee: "user1@foobarcorp.example.com"})
+	new.Comments = append(new.Comments, bugzilla.Comment{Count: 2, Creator: "user2@foobarcorp.example.com", Text: "Fixed"})
+	new.Attachments = []bugzilla.Attachment{
+		{ID: 1, Summary: "log", IsObsolete: 1},
+		{ID: 2, Summary: "new log"},
+	}
+
+	diff := bugzilla.Diff(old, new)
+	c.Assert(diff.Empty(), Equals, false)
+	c.Check(diff.ID, Equals, 1047068)
+	c.Check(diff.Fields, DeepEquals, []bugzilla.FieldDiff{
+		{Field: "status", Old: old.Status, New: "RESOLVED"},
+		{Field: "resolution", Old: "", New: "FIXED"},
+	})
+	c.Check(diff.Lists, DeepEquals, []bugzilla.ListDiff{
+		{Field: "keywords", Added: []string{"FIXED_UPSTREAM"}, Removed: []string{"TRETA_ADDRESSED"}},
+		{Field: "blocks", Added: []string{"1000"}},
+	})
+
+	c.Assert(diff.Flags, HasLen, 3)
+	c.Check(diff.Flags[0].ID, Equals, 266294)
+	c.Check(diff.Flags[0].Old.Requestee, Equals, "user3@foobarcorp.example.com")
+	c.Check(diff.Flags[0].New.Requestee, Equals, "user2@foobarcorp.example.com")
+	c.Check(diff.Flags[1].ID, Equals, 300000)
+	c.Check(diff.Flags[1].Old, IsNil)
+	c.Check(diff.Flags[2].ID, Equals, 264343)
+	c.Check(diff.Flags[2].New, IsNil)
+
+	c.Assert(diff.NewComments, HasLen, 1)
+	c.Check(diff.NewComments[0].Text, Equals, "Fixed")
+	c.Assert(diff.NewAttachments, HasLen, 1)
+	c.Check(diff.NewAttachments[0].ID, Equals, 2)
+	c.Assert(diff.ObsoletedAttachments, HasLen, 1)
+	c.Check(diff.ObsoletedAttachments[0].ID, Equals, 1)
+
+	c.Check(diff.String(), Equals, `Bug 1047068:
+  status: "`+old.Status+`" -> "RESOLVED"
+  resolution: "" -> "FIXED"
+  keywords: +FIXED_UPSTREAM -TRETA_ADDRESSED
+  blocks: +1000
+  flag 266294: needinfo?(user3@foobarcorp.example.com) -> needinfo?(user2@foobarcorp.example.com)
+  flag 300000: (none) -> needinfo?(user1@foobarcorp.example.com)
+  flag 264343: needinfo?(user1@foobarcorp.example.com) -> (none)
+  new comment #2 by user2@foobarcorp.example.com
+  new attachment 2: new log
+  obsoleted attachment 1: log
+`)
+}
+
+func (cs *clientSuite) TestDiffJSON(c *C) {
+	old, new := cs.sampleSnapshots(c)
+	new.Severity = "Critical"
+	new.CC = new.CC[1:]
+
+	data, err := json.Marshal(bugzilla.Diff(old, new))
+	c.Assert(err, IsNil)
+	ja := jsonassert.New(c)
+	ja.Assertf(string(data), `{
+		"id": 1047068,
+		"fields": [{"field": "severity", "old": "Major", "new": "Critical"}],
+		"lists": [{"field": "cc", "removed": ["561726581864@foobarcorp.example.com"]}]
+	}`)
+}
@@ -0,0 +1,93 @@
+package bugzilla
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// UpdateRequest is a request that an update would send, as returned by the
+// DryRun methods. The API key is left out of URL, so that it can be safely
+// logged.
+type UpdateRequest struct {
+	Method string
+	URL    string
+	Body   []byte
+}
+
+func (c *Client) updateRequest(up *updBug) (*UpdateRequest, error) {
+	rawURL, err := c.getUpdateBugsURL(up.Ids, map[string]string{})
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.encodeUpdate(up)
+	if err != nil {
+		return nil, err
+	}
+	return &UpdateRequest{Method: http.MethodPut, URL: rawURL, Body: body}, nil
+}
+
+// dryRunRequest is updateRequest with the API key removed from the URL, as
+// returned by the DryRun methods
+func (c *Client) dryRunRequest(up *updBug) (*UpdateRequest, error) {
+	request, err := c.updateRequest(up)
+	if err != nil {
+		return nil, err
+	}
+	request.URL = redactURL(request.URL)
+	return request, nil
+}
+
+// redactURL removes the API key from rawURL
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	values := u.Query()
+	values.Del("Bugzilla_api_key")
+	u.RawQuery = values.Encode()
+	return u.String()
+}
+
+// DryRunUpdate does what Update does, including fetching the bug to look
+// for needinfos, but returns the request instead of sending it
+func (c *Client) DryRunUpdate(id int, changes Changes) (*UpdateRequest, error) {
+	return c.DryRunUpdateContext(context.Background(), id, changes)
+}
+
+// DryRunUpdateContext is DryRunUpdate with a context.Context
+func (c *Client) DryRunUpdateContext(ctx context.Context, id int, changes Changes) (*UpdateRequest, error) {
+	up, err := c.buildUpdate(ctx, id, changes)
+	if err != nil {
+		return nil, err
+	}
+	return c.dryRunRequest(up)
+}
+
+// DryRunApplyUpdate returns the request that ApplyUpdate would send. It
+// doesn't contact the server.
+func (c *Client) DryRunApplyUpdate(id int, update *BugUpdate) (*UpdateRequest, error) {
+	if err := update.validate(); err != nil {
+		return nil, err
+	}
+	return c.dryRunRequest(update.forBugs([]int{id}))
+}
+
+// DryRunUpdateMany returns the requests that UpdateMany would send, one for
+// each chunk of ids. It doesn't contact the server.
+func (c *Client) DryRunUpdateMany(ids []int, update *BugUpdate) ([]UpdateRequest, error) {
+	if err := update.validate(); err != nil {
+		return nil, err
+	}
+	chunks := chunkIds(ids, DefaultChunkSize)
+	requests := make([]UpdateRequest, 0, len(chunks))
+	for _, chunk := range chunks {
+		request, err := c.dryRunRequest(update.forBugs(chunk))
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, *request)
+	}
+	return requests, nil
+}
@@ -0,0 +1,88 @@
+package bugzilla_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	bugzilla "github.com/bhdn/go-bugzilla-rest"
+	"github.com/kinbiko/jsonassert"
+	. "gopkg.in/check.v1"
+)
+
+func (cs *clientSuite) TestDryRunUpdate(c *C) {
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "GET")
+		io.WriteString(w, bugsJson)
+	}))
+	defer ts0.Close()
+	config := bugzilla.Config{BaseURL: ts0.URL, ApiKey: "xxxxxx", Username: "user1@foobarcorp.example.com"}
+	bz, err := bugzilla.New(config)
+	c.Assert(err, IsNil)
+
+	changes := bugzilla.Changes{
+		RemoveNeedinfo: "user3@foobarcorp.example.com",
+		SetPriority:    "P1",
+		CcMyself:       true,
+	}
+	request, err := bz.DryRunUpdate(1047068, changes)
+	c.Assert(err, IsNil)
+	c.Check(request.Method, Equals, "PUT")
+	u, err := url.Parse(request.URL)
+	c.Assert(err, IsNil)
+	c.Check(u.Path, Equals, "/rest/bug/1047068")
+	c.Check(u.Query().Get("Bugzilla_api_key"), Equals, "")
+	c.Check(request.URL, Not(Matches), ".*xxxxxx.*")
+	ja := jsonassert.New(c)
+	ja.Assertf(string(request.Body), `{
+		"ids": [1047068],
+		"flags": [{"status": "X", "id": 266294}, {"status": "X", "id": 266299}],
+		"priority": "P1 - Urgent",
+		"cc": {"add": ["user1@foobarcorp.example.com"]}
+	}`)
+
+	// The local checks still fail as they would in Update
+	_, err = bz.DryRunUpdate(1047068, bugzilla.Changes{SetPriority: "P9"})
+	c.Check(err, ErrorMatches, ".*invalid priority value: P9.*")
+}
+
+func (cs *clientSuite) TestDryRunUpdateMany(c *C) {
+	bz := makeClient("http://bz.foobarcorp.example.com")
+
+	ids := make([]int, 0, 150)
+	for id := 1; id <= 150; id++ {
+		ids = append(ids, id)
+	}
+	requests, err := bz.DryRunUpdateMany(ids, bugzilla.NewUpdate().SetTargetMilestone("2.0"))
+	c.Assert(err, IsNil)
+	c.Assert(requests, HasLen, 2)
+	c.Check(requests[1].Method, Equals, "PUT")
+	ja := jsonassert.New(c)
+	ja.Assertf(string(requests[1].Body), `{"ids": "<<PRESENCE>>", "target_milestone": "2.0"}`)
+
+	request, err := bz.DryRunApplyUpdate(101234, bugzilla.NewUpdate().AddKeyword("TRETA"))
+	c.Assert(err, IsNil)
+	ja.Assertf(string(request.Body), `{"ids": [101234], "keywords": {"add": ["TRETA"]}}`)
+}
+
+func (cs *clientSuite) TestDryRunKeepsApiKeyInUpdates(c *C) {
+	keys := make(chan string, 1)
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Check(r.Method, Equals, "PUT")
+		keys <- r.URL.Query().Get("Bugzilla_api_key")
+		io.WriteString(w, emptyUpdateResponse)
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	update := bugzilla.NewUpdate().SetSeverity("Critical")
+	request, err := bz.DryRunApplyUpdate(101234, update)
+	c.Assert(err, IsNil)
+	c.Check(request.URL, Not(Matches), ".*xxxxxx.*")
+
+	// Only the dry-run request has the key removed
+	_, err = bz.ApplyUpdate(101234, update)
+	c.Assert(err, IsNil)
+	c.Check(<-keys, Equals, "xxxxxx")
+}
@@ -0,0 +1,278 @@
+package bugzilla
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileCacherOptions sets the limits of a FileCacher. Zeroed fields mean no
+// limit.
+type FileCacherOptions struct {
+	// TTL is for how long entries are kept
+	TTL time.Duration
+	// MaxSize is the maximum size in bytes of all the entries together.
+	// The oldest entries are evicted when it is exceeded.
+	MaxSize int64
+	// EvictInterval is the minimum time between two evictions, which walk
+	// the whole directory. Zero uses DefaultEvictInterval.
+	EvictInterval time.Duration
+}
+
+// DefaultEvictInterval is how often a FileCacher evicts entries when
+// FileCacherOptions.EvictInterval is not set
+const DefaultEvictInterval = time.Minute
+
+// FileCacher is a Cacher, CacheReader, KindCacher and CacheInvalidator that
+// keeps the entries in a directory, sharded by the last two digits of the
+// bug ID:
+//
+//	<dir>/68/1047068.json
+//	<dir>/68/1047068.comments.json
+//	<dir>/68/1047068.attachments.json
+//
+// Entries are written to temporary files and renamed into place, so readers
+// never see partial entries. A lock file in the directory serializes
+// writers and eviction among processes sharing it.
+//
+// Eviction happens on writes, at most once every EvictInterval or whenever
+// a tenth of MaxSize has been written since the last one, so the cache may
+// briefly go over MaxSize. Expired entries are never returned by GetReader.
+type FileCacher struct {
+	dir  string
+	opts FileCacherOptions
+
+	mu        sync.Mutex
+	lastEvict time.Time
+	written   int64
+}
+
+const (
+	fileCacheSuffix   = ".json"
+	fileCacheLockName = ".lock"
+)
+
+// NewFileCacher returns a *FileCacher using dir, creating it if needed
+func NewFileCacher(dir string, opts FileCacherOptions) (*FileCacher, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FileCacher{dir: dir, opts: opts}, nil
+}
+
+// CachesKind tells the Client to also cache comments and attachments
+func (f *FileCacher) CachesKind(kind string) bool {
+	return kind == CacheComments || kind == CacheAttachments
+}
+
+// path returns the location of the entry for id, which is either a bug ID
+// or "<bug ID>/<kind>"
+func (f *FileCacher) path(id string) string {
+	parts := strings.SplitN(id, "/", 2)
+	bugID, err := strconv.Atoi(parts[0])
+	if err != nil || bugID < 0 {
+		return filepath.Join(f.dir, "other", url.PathEscape(id)+fileCacheSuffix)
+	}
+	name := parts[0]
+	if len(parts) == 2 {
+		name += "." + url.PathEscape(parts[1])
+	}
+	shard := fmt.Sprintf("%02d", bugID%100)
+	return filepath.Join(f.dir, shard, name+fileCacheSuffix)
+}
+
+func (f *FileCacher) lock(exclusive bool) (func(), error) {
+	return lockFile(filepath.Join(f.dir, fileCacheLockName), exclusive)
+}
+
+func (f *FileCacher) expired(modTime time.Time) bool {
+	return f.opts.TTL > 0 && time.Since(modTime) >= f.opts.TTL
+}
+
+// fileCacheWriter writes to a temporary file that replaces the entry when
+// closed
+type fileCacheWriter struct {
+	cacher  *FileCacher
+	target  string
+	tmp     *os.File
+	written int64
+	err     error
+}
+
+func (w *fileCacheWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.tmp.Write(p)
+	w.written += int64(n)
+	if err != nil {
+		w.err = err
+	}
+	return n, err
+}
+
+func (w *fileCacheWriter) Close() error {
+	if w.tmp == nil {
+		return w.err
+	}
+	tmpName := w.tmp.Name()
+	if err := w.tmp.Close(); err != nil && w.err == nil {
+		w.err = err
+	}
+	w.tmp = nil
+	if w.err != nil {
+		os.Remove(tmpName)
+		return w.err
+	}
+
+	unlock, err := w.cacher.lock(true)
+	if err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	defer unlock()
+	if err := os.Rename(tmpName, w.target); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if !w.cacher.evictDue(w.written) {
+		return nil
+	}
+	return w.cacher.evict()
+}
+
+// GetWriter returns a writer for the entry id. Errors are reported by Write
+// and Close.
+func (f *FileCacher) GetWriter(id string) io.WriteCloser {
+	target := f.path(id)
+	w := &fileCacheWriter{cacher: f, target: target}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		w.err = err
+		return w
+	}
+	w.tmp, w.err = ioutil.TempFile(filepath.Dir(target), ".tmp-")
+	return w
+}
+
+// GetReader opens the entry id, returning when it was written. Expired
+// entries are removed and reported as missing.
+func (f *FileCacher) GetReader(id string) (io.ReadCloser, time.Time, error) {
+	unlock, err := f.lock(false)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer unlock()
+
+	file, err := os.Open(f.path(id))
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, time.Time{}, err
+	}
+	if f.expired(info.ModTime()) {
+		file.Close()
+		os.Remove(f.path(id))
+		return nil, time.Time{}, os.ErrNotExist
+	}
+	return file, info.ModTime(), nil
+}
+
+// Invalidate removes the entry id
+func (f *FileCacher) Invalidate(id string) error {
+	unlock, err := f.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := os.Remove(f.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+type fileCacheEntry struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+func (f *FileCacher) entries() ([]fileCacheEntry, error) {
+	entries := make([]fileCacheEntry, 0)
+	err := filepath.Walk(f.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(info.Name(), fileCacheSuffix) ||
+			strings.HasPrefix(info.Name(), ".") {
+			return nil
+		}
+		entries = append(entries, fileCacheEntry{path: path, size: info.Size(), modTime: info.ModTime()})
+		return nil
+	})
+	return entries, err
+}
+
+// evictDue accounts for n bytes written and tells whether it is time to
+// evict entries
+func (f *FileCacher) evictDue(n int64) bool {
+	if f.opts.TTL <= 0 && f.opts.MaxSize <= 0 {
+		return false
+	}
+	interval := f.opts.EvictInterval
+	if interval <= 0 {
+		interval = DefaultEvictInterval
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.written += n
+	if !f.lastEvict.IsZero() && time.Since(f.lastEvict) < interval &&
+		(f.opts.MaxSize <= 0 || f.written <= f.opts.MaxSize/10) {
+		return false
+	}
+	f.lastEvict = time.Now()
+	f.written = 0
+	return true
+}
+
+// evict removes expired entries and then the oldest ones until the cache
+// fits MaxSize. It must be called with the exclusive lock held.
+func (f *FileCacher) evict() error {
+	if f.opts.TTL <= 0 && f.opts.MaxSize <= 0 {
+		return nil
+	}
+	entries, err := f.entries()
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].modTime.Before(entries[j].modTime)
+	})
+	var total int64
+	for _, entry := range entries {
+		total += entry.size
+	}
+	for _, entry := range entries {
+		if !f.expired(entry.modTime) && (f.opts.MaxSize <= 0 || total <= f.opts.MaxSize) {
+			break
+		}
+		if err := os.Remove(entry.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		total -= entry.size
+	}
+	return nil
+}
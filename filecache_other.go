@@ -0,0 +1,10 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package bugzilla
+
+// lockFile is a no-op where flock(2) is not available. Entries are still
+// replaced atomically, but eviction may race with other processes.
+func lockFile(path string, exclusive bool) (func(), error) {
+	return func() {}, nil
+}
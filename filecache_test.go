@@ -0,0 +1,216 @@
+package bugzilla_test
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	bugzilla "github.com/bhdn/go-bugzilla-rest"
+	. "gopkg.in/check.v1"
+)
+
+func writeEntry(c *C, cacher *bugzilla.FileCacher, id string, contents string) {
+	writer := cacher.GetWriter(id)
+	_, err := io.WriteString(writer, contents)
+	c.Assert(err, IsNil)
+	c.Assert(writer.Close(), IsNil)
+}
+
+func readEntry(cacher *bugzilla.FileCacher, id string) (string, error) {
+	reader, _, err := cacher.GetReader(id)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	return string(data), err
+}
+
+func (cs *clientSuite) TestFileCacher(c *C) {
+	dir := c.MkDir()
+	cacher, err := bugzilla.NewFileCacher(dir, bugzilla.FileCacherOptions{})
+	c.Assert(err, IsNil)
+
+	_, err = readEntry(cacher, "1047068")
+	c.Assert(os.IsNotExist(err), Equals, true)
+
+	writeEntry(c, cacher, "1047068", `{"id": 1047068}`)
+	writeEntry(c, cacher, "1047068/comments", `[]`)
+	writeEntry(c, cacher, "1047068", `{"id": 1047068, "summary": "updated"}`)
+
+	contents, err := readEntry(cacher, "1047068")
+	c.Assert(err, IsNil)
+	c.Check(contents, Equals, `{"id": 1047068, "summary": "updated"}`)
+
+	_, err = os.Stat(filepath.Join(dir, "68", "1047068.json"))
+	c.Check(err, IsNil)
+	_, err = os.Stat(filepath.Join(dir, "68", "1047068.comments.json"))
+	c.Check(err, IsNil)
+
+	// No temporary files left behind
+	files, err := filepath.Glob(filepath.Join(dir, "68", ".tmp-*"))
+	c.Assert(err, IsNil)
+	c.Check(files, HasLen, 0)
+}
+
+func (cs *clientSuite) TestFileCacherTTL(c *C) {
+	dir := c.MkDir()
+	cacher, err := bugzilla.NewFileCacher(dir, bugzilla.FileCacherOptions{TTL: time.Hour})
+	c.Assert(err, IsNil)
+
+	writeEntry(c, cacher, "1", `{"id": 1}`)
+	_, err = readEntry(cacher, "1")
+	c.Assert(err, IsNil)
+
+	old := time.Now().Add(-2 * time.Hour)
+	path := filepath.Join(dir, "01", "1.json")
+	c.Assert(os.Chtimes(path, old, old), IsNil)
+	_, err = readEntry(cacher, "1")
+	c.Assert(os.IsNotExist(err), Equals, true)
+	_, err = os.Stat(path)
+	c.Check(os.IsNotExist(err), Equals, true)
+}
+
+func (cs *clientSuite) TestFileCacherEvictInterval(c *C) {
+	dir := c.MkDir()
+	cacher, err := bugzilla.NewFileCacher(dir, bugzilla.FileCacherOptions{TTL: time.Hour, EvictInterval: time.Hour})
+	c.Assert(err, IsNil)
+
+	writeEntry(c, cacher, "1", `{"id": 1}`)
+	old := time.Now().Add(-2 * time.Hour)
+	path := filepath.Join(dir, "01", "1.json")
+	c.Assert(os.Chtimes(path, old, old), IsNil)
+
+	// The directory is not walked again before EvictInterval
+	writeEntry(c, cacher, "2", `{"id": 2}`)
+	_, err = os.Stat(path)
+	c.Check(err, IsNil)
+
+	// But the expired entry is still not served
+	_, err = readEntry(cacher, "1")
+	c.Check(os.IsNotExist(err), Equals, true)
+}
+
+func (cs *clientSuite) TestFileCacherMaxSize(c *C) {
+	dir := c.MkDir()
+	cacher, err := bugzilla.NewFileCacher(dir, bugzilla.FileCacherOptions{MaxSize: 25})
+	c.Assert(err, IsNil)
+
+	for i, id := range []string{"1", "2", "3"} {
+		writeEntry(c, cacher, id, `{"id": `+id+`}`)
+		// Make the modification times distinct
+		when := time.Now().Add(time.Duration(i-10) * time.Minute)
+		c.Assert(os.Chtimes(filepath.Join(dir, "0"+id, id+".json"), when, when), IsNil)
+	}
+	writeEntry(c, cacher, "4", `{"id": 4}`)
+
+	// Each entry has 9 bytes, so only two fit
+	_, err = readEntry(cacher, "1")
+	c.Check(os.IsNotExist(err), Equals, true)
+	_, err = readEntry(cacher, "2")
+	c.Check(os.IsNotExist(err), Equals, true)
+	_, err = readEntry(cacher, "3")
+	c.Check(err, IsNil)
+	_, err = readEntry(cacher, "4")
+	c.Check(err, IsNil)
+}
+
+func (cs *clientSuite) TestGetBugWithFileCacher(c *C) {
+	ts0 := cs.makeBugzillaRestServer(c, 1047068)
+	defer ts0.Close()
+	dir := c.MkDir()
+	cacher, err := bugzilla.NewFileCacher(dir, bugzilla.FileCacherOptions{})
+	c.Assert(err, IsNil)
+	bz := makeClientWithCacheConfig(ts0.URL, cacher, time.Hour, false)
+
+	_, err = bz.GetBug(1047068)
+	c.Assert(err, IsNil)
+	for _, name := range []string{"1047068.json", "1047068.comments.json", "1047068.attachments.json"} {
+		_, err = os.Stat(filepath.Join(dir, "68", name))
+		c.Check(err, IsNil)
+	}
+
+	// Served from the cache even with the server gone
+	ts0.Close()
+	bug, err := bz.GetBug(1047068)
+	c.Assert(err, IsNil)
+	c.Check(bug.Summary, Equals, "L4: test cloud bug123")
+	c.Check(bug.Comments, HasLen, 4)
+}
+
+func (cs *clientSuite) TestGetCommentsWithFileCacher(c *C) {
+	lastChange := "2023-04-12T01:02:03Z"
+	ts0, counts := cs.makeCountingServer(c, &lastChange)
+	defer ts0.Close()
+	dir := c.MkDir()
+	cacher, err := bugzilla.NewFileCacher(dir, bugzilla.FileCacherOptions{})
+	c.Assert(err, IsNil)
+	bz := makeClientWithCacheConfig(ts0.URL, cacher, time.Hour, false)
+
+	_, err = bz.GetBug(1047068)
+	c.Assert(err, IsNil)
+	c.Check(counts["/rest/bug/1047068/comment"], Equals, 1)
+	c.Check(counts["/rest/bug/1047068/attachment"], Equals, 1)
+
+	// Served from the entries written by GetBug
+	comments, err := bz.GetComments([]int{1047068})
+	c.Assert(err, IsNil)
+	c.Check(comments, HasLen, 4)
+	attachments, err := bz.GetAttachmentsInfo([]int{1047068})
+	c.Assert(err, IsNil)
+	c.Check(attachments, HasLen, 11)
+	c.Check(counts["/rest/bug/1047068/comment"], Equals, 1)
+	c.Check(counts["/rest/bug/1047068/attachment"], Equals, 1)
+
+	// Stale entries are fetched again
+	old := time.Now().Add(-2 * time.Hour)
+	c.Assert(os.Chtimes(filepath.Join(dir, "68", "1047068.comments.json"), old, old), IsNil)
+	_, err = bz.GetComments([]int{1047068})
+	c.Assert(err, IsNil)
+	c.Check(counts["/rest/bug/1047068/comment"], Equals, 2)
+}
+
+func (cs *clientSuite) TestGetBugsSkipsCachedComments(c *C) {
+	var mu sync.Mutex
+	comments := []string{`{"id": 1, "bug_id": 11, "text": "first"}`}
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/comment"):
+			fmt.Fprintf(w, `{"bugs": {"11": {"comments": [%s]}}}`, strings.Join(comments, ","))
+		case strings.HasSuffix(r.URL.Path, "/attachment"):
+			io.WriteString(w, `{"bugs": {"11": []}}`)
+		default:
+			fmt.Fprintf(w, `{"bugs": [{"id": 11, "last_change_time": "2023-04-12T0%d:00:00Z"}]}`, len(comments))
+		}
+	}))
+	defer ts0.Close()
+	cacher, err := bugzilla.NewFileCacher(c.MkDir(), bugzilla.FileCacherOptions{})
+	c.Assert(err, IsNil)
+	bz := makeClientWithCacheConfig(ts0.URL, cacher, time.Hour, false)
+
+	opts := bugzilla.GetBugsOptions{WithComments: true, WithAttachments: true}
+	bugs, err := bz.GetBugs([]int{11}, opts)
+	c.Assert(err, IsNil)
+	c.Check(bugs[0].Comments, HasLen, 1)
+
+	mu.Lock()
+	comments = append(comments, `{"id": 2, "bug_id": 11, "text": "second"}`)
+	mu.Unlock()
+
+	// The fresh bug comes with its fresh comments, not the cached ones
+	bugs, err = bz.GetBugs([]int{11}, opts)
+	c.Assert(err, IsNil)
+	c.Check(bugs[0].Comments, HasLen, 2)
+	bug, err := bz.GetBug(11)
+	c.Assert(err, IsNil)
+	c.Check(bug.Comments, HasLen, 2)
+}
@@ -0,0 +1,202 @@
+package bugzilla
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Flag statuses
+const (
+	FlagStatusRequested = "?"
+	FlagStatusGranted   = "+"
+	FlagStatusDenied    = "-"
+	// FlagStatusCleared is only sent, to remove flags
+	FlagStatusCleared = "X"
+)
+
+// FlagFilter selects flags by their fields. Empty fields match any flag,
+// and emails are compared ignoring case.
+type FlagFilter struct {
+	Name      string
+	Status    string
+	Setter    string
+	Requestee string
+}
+
+func (f *FlagFilter) matches(flag *Flag) bool {
+	return (f.Name == "" || flag.Name == f.Name) &&
+		(f.Status == "" || flag.Status == f.Status) &&
+		(f.Setter == "" || strings.EqualFold(flag.Setter, f.Setter)) &&
+		(f.Requestee == "" || strings.EqualFold(flag.Requestee, f.Requestee))
+}
+
+func findFlags(flags []Flag, filter FlagFilter) []Flag {
+	found := make([]Flag, 0)
+	for i := range flags {
+		if filter.matches(&flags[i]) {
+			found = append(found, flags[i])
+		}
+	}
+	return found
+}
+
+// FindFlags returns the flags of the bug matching filter
+func (b *Bug) FindFlags(filter FlagFilter) []Flag {
+	return findFlags(b.Flags, filter)
+}
+
+// FindFlags returns the flags of the attachment matching filter
+func (a *Attachment) FindFlags(filter FlagFilter) []Flag {
+	return findFlags(a.Flags, filter)
+}
+
+// flagChanges builds the flags part of bug and attachment updates,
+// keeping the first invalid change as err. Changes are also checked with
+// types, when set, and with the flags already existing in the bug or
+// attachment, when known.
+type flagChanges struct {
+	changes  []flagChange
+	types    []FlagType
+	existing []Flag
+	err      error
+}
+
+func (f *flagChanges) add(change flagChange, allowed ...string) {
+	if change.Name == "" && change.TypeID == 0 && change.ID == 0 {
+		f.fail(RequestError{fmt.Errorf("flag change without name, type or ID")})
+		return
+	}
+	for _, status := range allowed {
+		if change.Status == status {
+			if f.types != nil {
+				if err := checkFlagType(&change, f.types); err != nil {
+					f.fail(err)
+					return
+				}
+			}
+			f.changes = append(f.changes, change)
+			return
+		}
+	}
+	f.fail(RequestError{fmt.Errorf("invalid status %q for flag %s, expected one of %q", change.Status, flagLabel(&change), allowed)})
+}
+
+func (f *flagChanges) fail(err error) {
+	if f.err == nil {
+		f.err = err
+	}
+}
+
+func flagLabel(change *flagChange) string {
+	switch {
+	case change.Name != "":
+		return change.Name
+	case change.ID != 0:
+		return fmt.Sprintf("%d", change.ID)
+	}
+	return fmt.Sprintf("of type %d", change.TypeID)
+}
+
+func (f *flagChanges) request(name string, typeID int, requestee string) {
+	f.add(flagChange{New: true, Name: name, TypeID: typeID, Requestee: requestee, Status: FlagStatusRequested},
+		FlagStatusRequested)
+}
+
+func (f *flagChanges) set(name string, status string) {
+	f.add(flagChange{Name: name, Status: status}, FlagStatusGranted, FlagStatusDenied)
+}
+
+func (f *flagChanges) setByID(id int, status string) {
+	f.add(flagChange{ID: id, Status: status}, FlagStatusRequested, FlagStatusGranted, FlagStatusDenied)
+}
+
+func (f *flagChanges) clear(ids ...int) {
+	for _, id := range ids {
+		f.add(flagChange{ID: id, Status: FlagStatusCleared}, FlagStatusCleared)
+	}
+}
+
+func flagIDs(flags []Flag) []int {
+	ids := make([]int, 0, len(flags))
+	for _, flag := range flags {
+		ids = append(ids, flag.ID)
+	}
+	return ids
+}
+
+// RequestFlag requests the flag name (as in "review?") from requestee,
+// which can be empty
+func (u *BugUpdate) RequestFlag(name string, requestee string) *BugUpdate {
+	u.flags.request(name, 0, requestee)
+	return u
+}
+
+// RequestFlagType is RequestFlag with the ID of the flag type, as returned
+// by GetFlagTypes
+func (u *BugUpdate) RequestFlagType(typeID int, requestee string) *BugUpdate {
+	u.flags.request("", typeID, requestee)
+	return u
+}
+
+// SetFlag sets the flag name to FlagStatusGranted or FlagStatusDenied,
+// creating it if the bug doesn't have it
+func (u *BugUpdate) SetFlag(name string, status string) *BugUpdate {
+	u.flags.set(name, status)
+	return u
+}
+
+// SetFlagByID changes the status of an existing flag
+func (u *BugUpdate) SetFlagByID(id int, status string) *BugUpdate {
+	u.flags.setByID(id, status)
+	return u
+}
+
+// ClearFlag removes flags by their IDs
+func (u *BugUpdate) ClearFlag(ids ...int) *BugUpdate {
+	u.flags.clear(ids...)
+	return u
+}
+
+// ClearFlags removes the given flags, usually found with Bug.FindFlags:
+//
+//	update.ClearFlags(bug.FindFlags(FlagFilter{Name: "review", Requestee: email}))
+func (u *BugUpdate) ClearFlags(flags []Flag) *BugUpdate {
+	return u.ClearFlag(flagIDs(flags)...)
+}
+
+// RequestFlag requests the flag name from requestee, which can be empty
+func (u *AttachmentUpdate) RequestFlag(name string, requestee string) *AttachmentUpdate {
+	u.flags.request(name, 0, requestee)
+	return u
+}
+
+// RequestFlagType is RequestFlag with the ID of the flag type
+func (u *AttachmentUpdate) RequestFlagType(typeID int, requestee string) *AttachmentUpdate {
+	u.flags.request("", typeID, requestee)
+	return u
+}
+
+// SetFlag sets the flag name to FlagStatusGranted or FlagStatusDenied,
+// creating it if the attachment doesn't have it
+func (u *AttachmentUpdate) SetFlag(name string, status string) *AttachmentUpdate {
+	u.flags.set(name, status)
+	return u
+}
+
+// SetFlagByID changes the status of an existing flag
+func (u *AttachmentUpdate) SetFlagByID(id int, status string) *AttachmentUpdate {
+	u.flags.setByID(id, status)
+	return u
+}
+
+// ClearFlag removes flags by their IDs
+func (u *AttachmentUpdate) ClearFlag(ids ...int) *AttachmentUpdate {
+	u.flags.clear(ids...)
+	return u
+}
+
+// ClearFlags removes the given flags, usually found with
+// Attachment.FindFlags
+func (u *AttachmentUpdate) ClearFlags(flags []Flag) *AttachmentUpdate {
+	return u.ClearFlag(flagIDs(flags)...)
+}
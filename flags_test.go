@@ -0,0 +1,119 @@
+package bugzilla_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"time"
+
+	bugzilla "github.com/bhdn/go-bugzilla-rest"
+	"github.com/kinbiko/jsonassert"
+	. "gopkg.in/check.v1"
+)
+
+func (cs *clientSuite) TestFindFlags(c *C) {
+	bz := makeClient("http://bz.foobarcorp.example.com")
+	bug, err := bz.GetBugFromJSON(strings.NewReader(sampleJSON))
+	c.Assert(err, IsNil)
+
+	flags := bug.FindFlags(bugzilla.FlagFilter{Name: "needinfo", Requestee: "USER3@foobarcorp.example.com"})
+	c.Assert(flags, HasLen, 2)
+	c.Check(flags[0].ID, Equals, 266294)
+	c.Check(flags[1].ID, Equals, 266299)
+
+	flags = bug.FindFlags(bugzilla.FlagFilter{Status: bugzilla.FlagStatusGranted})
+	c.Assert(flags, HasLen, 1)
+	c.Check(flags[0].Name, Equals, "CCB_Review")
+
+	flags = bug.FindFlags(bugzilla.FlagFilter{Setter: "user1@foobarcorp.example.com", Status: "?"})
+	c.Check(flags, HasLen, 2)
+	c.Check(bug.FindFlags(bugzilla.FlagFilter{}), HasLen, 5)
+	c.Check(bug.FindFlags(bugzilla.FlagFilter{Name: "review"}), HasLen, 0)
+
+	attachment := bugzilla.Attachment{Flags: []bugzilla.Flag{{ID: 1, Name: "review", Status: "?", Requestee: "user1@foobarcorp.example.com"}}}
+	c.Check(attachment.FindFlags(bugzilla.FlagFilter{Name: "review"}), HasLen, 1)
+}
+
+func (cs *clientSuite) TestUpdateFlags(c *C) {
+	bz := makeClient("http://bz.foobarcorp.example.com")
+	bug, err := bz.GetBugFromJSON(strings.NewReader(sampleJSON))
+	c.Assert(err, IsNil)
+
+	update := bugzilla.NewUpdate().
+		RequestFlag("review", "user2@foobarcorp.example.com").
+		RequestFlagType(7, "").
+		SetFlag("qe_ack", bugzilla.FlagStatusGranted).
+		SetFlagByID(264345, bugzilla.FlagStatusDenied).
+		ClearFlags(bug.FindFlags(bugzilla.FlagFilter{Name: "needinfo", Requestee: "user3@foobarcorp.example.com"}))
+	request, err := bz.DryRunApplyUpdate(1047068, update)
+	c.Assert(err, IsNil)
+	ja := jsonassert.New(c)
+	ja.Assertf(string(request.Body), `{"ids": [1047068], "flags": [
+		{"new": true, "name": "review", "requestee": "user2@foobarcorp.example.com", "status": "?"},
+		{"new": true, "type_id": 7, "status": "?"},
+		{"name": "qe_ack", "status": "+"},
+		{"id": 264345, "status": "-"},
+		{"id": 266294, "status": "X"},
+		{"id": 266299, "status": "X"}
+	]}`)
+}
+
+func (cs *clientSuite) TestUpdateFlagsInvalid(c *C) {
+	bz := makeClient("http://bz.foobarcorp.example.com")
+
+	_, err := bz.DryRunApplyUpdate(1047068, bugzilla.NewUpdate().SetFlag("qe_ack", "?"))
+	c.Check(err, ErrorMatches, `.*invalid status "\?" for flag qe_ack.*`)
+	_, err = bz.ApplyUpdate(1047068, bugzilla.NewUpdate().SetFlagByID(1, "X"))
+	c.Check(err, ErrorMatches, `.*invalid status "X" for flag 1.*`)
+	_, err = bz.UpdateAttachment(1, bugzilla.NewAttachmentUpdate().RequestFlag("", ""))
+	c.Check(err, ErrorMatches, ".*flag change without name, type or ID.*")
+}
+
+func (cs *clientSuite) TestUpdateAttachment(c *C) {
+	ts0, queries, nextJson, processBug := makeBugzillaServerWithChannels()
+	defer ts0.Close()
+	cacher, err := bugzilla.NewFileCacher(c.MkDir(), bugzilla.FileCacherOptions{})
+	c.Assert(err, IsNil)
+	bz := makeClientWithCacheConfig(ts0.URL, cacher, time.Hour, false)
+
+	nextJson <- bugsJson
+	nextJson <- bugsCommentsJson
+	nextJson <- bugsAttachmentsJson
+	_, err = bz.GetBug(1047068)
+	c.Assert(err, IsNil)
+
+	update := bugzilla.NewAttachmentUpdate().
+		SetFlag("review", bugzilla.FlagStatusGranted).
+		SetObsolete(true).
+		AddComment("Looks good")
+	processBug <- `{"attachments": [{"id": 766283, "changes": {"flagtypes.name": {"added": "review+", "removed": "review?(user1@foobarcorp.example.com)"}}, "last_change_time": "2023-05-09T09:53:05Z"}]}`
+	nextJson <- `{"attachments": {"766283": {"bug_id": 1047068}}, "bugs": {}}`
+	result, err := bz.UpdateAttachment(766283, update)
+	c.Assert(err, IsNil)
+	c.Check(result.Id, Equals, 766283)
+	c.Check(result.Changes["flagtypes.name"].Added, Equals, "review+")
+	ja := jsonassert.New(c)
+	ja.Assertf(<-queries, `{"ids": [766283], "comment": "Looks good", "is_obsolete": true, "flags": [{"name": "review", "status": "+"}]}`)
+	for _, id := range []string{"1047068", "1047068/attachments"} {
+		_, _, err = cacher.GetReader(id)
+		c.Check(os.IsNotExist(err), Equals, true)
+	}
+	_, _, err = cacher.GetReader("1047068/comments")
+	c.Check(err, IsNil)
+}
+
+func (cs *clientSuite) TestUpdateAttachmentPath(c *C) {
+	paths := make(chan string, 1)
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths <- r.Method + " " + r.URL.Path
+		io.WriteString(w, `{"attachments": [{"id": 766283, "changes": {}}]}`)
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	_, err := bz.UpdateAttachment(766283, bugzilla.NewAttachmentUpdate().ClearFlag(10))
+	c.Assert(err, IsNil)
+	c.Check(<-paths, Equals, "PUT /rest/bug/attachment/766283")
+}
@@ -0,0 +1,199 @@
+package bugzilla
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// FlagType describes a flag that can be set on the bugs or attachments of
+// a product and component
+type FlagType struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Type is either "bug" or "attachment"
+	Type string `json:"type"`
+	// Values are the statuses the user can set
+	Values          []string `json:"values"`
+	IsRequestable   bool     `json:"is_requestable"`
+	IsRequesteeble  bool     `json:"is_requesteeble"`
+	IsMultiplicable bool     `json:"is_multiplicable"`
+	GrantGroup      int      `json:"grant_group"`
+	RequestGroup    int      `json:"request_group"`
+}
+
+// FlagTypes has the flag types available for bugs and for attachments, as
+// returned by GetFlagTypes
+type FlagTypes struct {
+	Bug        []FlagType `json:"bug"`
+	Attachment []FlagType `json:"attachment"`
+}
+
+func (c *Client) getFlagTypesURL(product string, component string) (string, error) {
+	segments := []string{product}
+	if component != "" {
+		segments = append(segments, component)
+	}
+	return c.makeSegmentsURL("/rest/flag_types", segments, c.valuesFromMap(map[string]string{}))
+}
+
+func (c *Client) decodeFlagTypes(data []byte) (*FlagTypes, error) {
+	var result FlagTypes
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, ConnectionError{err}
+	}
+	return &result, nil
+}
+
+// GetFlagTypes returns the flag types available in product and component.
+// With an empty component, it returns those of any component of product.
+func (c *Client) GetFlagTypes(product string, component string) (*FlagTypes, error) {
+	return c.GetFlagTypesContext(context.Background(), product, component)
+}
+
+// GetFlagTypesContext is GetFlagTypes with a context.Context
+func (c *Client) GetFlagTypesContext(ctx context.Context, product string, component string) (*FlagTypes, error) {
+	url, err := c.getFlagTypesURL(product, component)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return c.decodeFlagTypes(body)
+}
+
+// findFlagType returns the type in types of the flag changed by change, if
+// it can be told by its name or type ID
+func findFlagType(change *flagChange, types []FlagType) *FlagType {
+	if change.Name == "" && change.TypeID == 0 {
+		return nil
+	}
+	for i := range types {
+		if (change.TypeID != 0 && types[i].ID == change.TypeID) ||
+			(change.TypeID == 0 && types[i].Name == change.Name) {
+			return &types[i]
+		}
+	}
+	return nil
+}
+
+// checkFlagType tells whether change is allowed by types. Changes made by
+// flag ID are not checked, as the type of the flag is not known.
+func checkFlagType(change *flagChange, types []FlagType) error {
+	if change.Name == "" && change.TypeID == 0 {
+		return nil
+	}
+	flagType := findFlagType(change, types)
+	if flagType == nil {
+		return RequestError{fmt.Errorf("flag %s is not available", flagLabel(change))}
+	}
+	allowed := false
+	for _, value := range flagType.Values {
+		if value == change.Status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return RequestError{fmt.Errorf("flag %s can't be set to %q, only to %q", flagType.Name, change.Status, flagType.Values)}
+	}
+	if change.Status == FlagStatusRequested && !flagType.IsRequestable {
+		return RequestError{fmt.Errorf("flag %s can't be requested", flagType.Name)}
+	}
+	if change.Requestee != "" && !flagType.IsRequesteeble {
+		return RequestError{fmt.Errorf("flag %s can't be requested from someone (%s)", flagType.Name, change.Requestee)}
+	}
+	return nil
+}
+
+// checkTypes validates all the changes with types and keeps types to
+// validate those added later
+func (f *flagChanges) checkTypes(types []FlagType, existing []Flag) {
+	f.types = append(make([]FlagType, 0, len(types)), types...)
+	f.existing = append(make([]Flag, 0, len(existing)), existing...)
+	for i := range f.changes {
+		if err := checkFlagType(&f.changes[i], types); err != nil {
+			f.fail(err)
+		}
+	}
+}
+
+// checkMultiplicable fails when more than one flag of a type that is not
+// multiplicable would exist after the changes, counting the existing flags
+// not cleared by them
+func (f *flagChanges) checkMultiplicable() error {
+	cleared := make(map[int]bool)
+	for _, change := range f.changes {
+		if change.ID != 0 && change.Status == FlagStatusCleared {
+			cleared[change.ID] = true
+		}
+	}
+	count := make(map[int]int)
+	for _, flag := range f.existing {
+		if !cleared[flag.ID] {
+			count[flag.TypeID]++
+		}
+	}
+	for i := range f.changes {
+		if !f.changes[i].New {
+			continue
+		}
+		flagType := findFlagType(&f.changes[i], f.types)
+		if flagType == nil || flagType.IsMultiplicable {
+			continue
+		}
+		count[flagType.ID]++
+		if count[flagType.ID] > 1 {
+			return RequestError{fmt.Errorf("flag %s can't be set more than once", flagType.Name)}
+		}
+	}
+	return nil
+}
+
+// validate returns the first error found in the changes
+func (f *flagChanges) validate() error {
+	if f.err != nil {
+		return f.err
+	}
+	if f.types == nil {
+		return nil
+	}
+	return f.checkMultiplicable()
+}
+
+// CheckFlags has the flag changes of the update, those added before and
+// after it, validated with the bug flag types in types, as returned by
+// GetFlagTypes for the product and component of the bug. Errors are
+// returned when the update is applied.
+//
+// Without CheckFlags or CheckFlagsOn, flag changes are only validated by
+// the server. That is always the case of the needinfo flags changed by
+// Update with Changes.
+func (u *BugUpdate) CheckFlags(types *FlagTypes) *BugUpdate {
+	u.flags.checkTypes(types.Bug, nil)
+	return u
+}
+
+// CheckFlagsOn is CheckFlags also taking into account the flags the bug
+// already has, as in Bug.Flags, so that requesting a second flag of a type
+// that is not multiplicable fails
+func (u *BugUpdate) CheckFlagsOn(types *FlagTypes, flags []Flag) *BugUpdate {
+	u.flags.checkTypes(types.Bug, flags)
+	return u
+}
+
+// CheckFlags is BugUpdate.CheckFlags with the attachment flag types
+func (u *AttachmentUpdate) CheckFlags(types *FlagTypes) *AttachmentUpdate {
+	u.flags.checkTypes(types.Attachment, nil)
+	return u
+}
+
+// CheckFlagsOn is BugUpdate.CheckFlagsOn with the attachment flag types
+// and the flags of the attachment, as in Attachment.Flags
+func (u *AttachmentUpdate) CheckFlagsOn(types *FlagTypes, flags []Flag) *AttachmentUpdate {
+	u.flags.checkTypes(types.Attachment, flags)
+	return u
+}
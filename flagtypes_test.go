@@ -0,0 +1,139 @@
+package bugzilla_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	bugzilla "github.com/bhdn/go-bugzilla-rest"
+	. "gopkg.in/check.v1"
+)
+
+const flagTypesJson = `
+{
+   "attachment" : [
+      {
+         "description" : "Code review",
+         "grant_group" : null,
+         "id" : 11,
+         "is_multiplicable" : true,
+         "is_requestable" : true,
+         "is_requesteeble" : true,
+         "name" : "review",
+         "request_group" : null,
+         "type" : "attachment",
+         "values" : ["X", "?", "+", "-"]
+      }
+   ],
+   "bug" : [
+      {
+         "description" : "Need more information",
+         "grant_group" : null,
+         "id" : 4,
+         "is_multiplicable" : true,
+         "is_requestable" : true,
+         "is_requesteeble" : true,
+         "name" : "needinfo",
+         "request_group" : null,
+         "type" : "bug",
+         "values" : ["X", "?", "+", "-"]
+      },
+      {
+         "description" : "QE acknowledgement",
+         "grant_group" : 15,
+         "id" : 7,
+         "is_multiplicable" : false,
+         "is_requestable" : true,
+         "is_requesteeble" : false,
+         "name" : "qe_ack",
+         "request_group" : null,
+         "type" : "bug",
+         "values" : ["X", "?", "+"]
+      }
+   ]
+}
+`
+
+func (cs *clientSuite) TestGetFlagTypes(c *C) {
+	paths := make(chan string, 1)
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths <- r.URL.EscapedPath()
+		io.WriteString(w, flagTypesJson)
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	types, err := bz.GetFlagTypes("Enterprise Frobnicator 9000.1", "Core")
+	c.Assert(err, IsNil)
+	c.Check(<-paths, Equals, "/rest/flag_types/Enterprise%20Frobnicator%209000.1/Core")
+	c.Assert(types.Bug, HasLen, 2)
+	c.Check(types.Bug[1], DeepEquals, bugzilla.FlagType{
+		ID:            7,
+		Name:          "qe_ack",
+		Description:   "QE acknowledgement",
+		Type:          "bug",
+		Values:        []string{"X", "?", "+"},
+		IsRequestable: true,
+		GrantGroup:    15,
+	})
+	c.Assert(types.Attachment, HasLen, 1)
+	c.Check(types.Attachment[0].Name, Equals, "review")
+
+	_, err = bz.GetFlagTypes("Frobnicator", "")
+	c.Assert(err, IsNil)
+	c.Check(<-paths, Equals, "/rest/flag_types/Frobnicator")
+
+	_, err = bz.GetFlagTypes("Frobnicator/Legacy", "..")
+	c.Assert(err, IsNil)
+	c.Check(<-paths, Equals, "/rest/flag_types/Frobnicator%2FLegacy/%2E%2E")
+}
+
+func (cs *clientSuite) TestCheckFlags(c *C) {
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, flagTypesJson)
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+	types, err := bz.GetFlagTypes("Frobnicator", "Core")
+	c.Assert(err, IsNil)
+
+	update := bugzilla.NewUpdate().
+		RequestFlag("needinfo", "user1@foobarcorp.example.com").
+		CheckFlags(types).
+		RequestFlagType(7, "").
+		SetFlagByID(1234, bugzilla.FlagStatusDenied)
+	_, err = bz.DryRunApplyUpdate(1047068, update)
+	c.Check(err, IsNil)
+
+	for _, t := range []struct {
+		update *bugzilla.BugUpdate
+		err    string
+	}{
+		{bugzilla.NewUpdate().RequestFlag("review", "").CheckFlags(types), ".*flag review is not available.*"},
+		{bugzilla.NewUpdate().CheckFlags(types).SetFlag("qe_ack", bugzilla.FlagStatusDenied), `.*flag qe_ack can't be set to "-".*`},
+		{bugzilla.NewUpdate().CheckFlags(types).RequestFlag("qe_ack", "user1@foobarcorp.example.com"), ".*flag qe_ack can't be requested from someone.*"},
+		{bugzilla.NewUpdate().CheckFlags(types).RequestFlagType(99, ""), ".*flag of type 99 is not available.*"},
+	} {
+		_, err = bz.DryRunApplyUpdate(1047068, t.update)
+		c.Check(err, ErrorMatches, t.err)
+	}
+
+	// qe_ack is not multiplicable
+	_, err = bz.DryRunApplyUpdate(1047068, bugzilla.NewUpdate().CheckFlags(types).
+		RequestFlag("qe_ack", "").RequestFlagType(7, ""))
+	c.Check(err, ErrorMatches, ".*flag qe_ack can't be set more than once.*")
+	existing := []bugzilla.Flag{{ID: 300, Name: "qe_ack", TypeID: 7, Status: "?"}}
+	_, err = bz.DryRunApplyUpdate(1047068, bugzilla.NewUpdate().CheckFlagsOn(types, existing).
+		RequestFlag("qe_ack", ""))
+	c.Check(err, ErrorMatches, ".*flag qe_ack can't be set more than once.*")
+	_, err = bz.DryRunApplyUpdate(1047068, bugzilla.NewUpdate().CheckFlagsOn(types, existing).
+		RequestFlag("qe_ack", "").ClearFlags(existing))
+	c.Check(err, IsNil)
+	_, err = bz.DryRunApplyUpdate(1047068, bugzilla.NewUpdate().CheckFlagsOn(types, existing).
+		RequestFlag("needinfo", "").RequestFlag("needinfo", "user1@foobarcorp.example.com"))
+	c.Check(err, IsNil)
+
+	attUpdate := bugzilla.NewAttachmentUpdate().CheckFlags(types).RequestFlag("needinfo", "")
+	_, err = bz.UpdateAttachment(766283, attUpdate)
+	c.Check(err, ErrorMatches, ".*flag needinfo is not available.*")
+}
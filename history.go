@@ -0,0 +1,97 @@
+package bugzilla
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// HistoryChange is the change of one field in a HistoryEntry
+type HistoryChange struct {
+	FieldName    string `json:"field_name"`
+	Removed      string `json:"removed"`
+	Added        string `json:"added"`
+	AttachmentID *int   `json:"attachment_id,omitempty"`
+}
+
+// HistoryEntry is a set of changes done by someone at a given time
+type HistoryEntry struct {
+	When    time.Time       `json:"when"`
+	Who     string          `json:"who"`
+	Changes []HistoryChange `json:"changes"`
+}
+
+// BugHistory is the history of changes of a bug
+type BugHistory struct {
+	ID      int            `json:"id"`
+	Alias   []string       `json:"alias"`
+	History []HistoryEntry `json:"history"`
+}
+
+type historyResult struct {
+	Bugs []BugHistory `json:"bugs"`
+}
+
+func (c *Client) getHistoryURL(bugIds []int, params map[string]string) (string, error) {
+	first, values, err := valuesFromBugIds(bugIds)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range params {
+		values.Add(k, v)
+	}
+	path := fmt.Sprintf("/rest/bug/%d/history", first)
+	return c.makeURL(path, values)
+}
+
+func (c *Client) decodeHistory(data []byte) ([]BugHistory, error) {
+	var result historyResult
+	err := json.Unmarshal(data, &result)
+	if err != nil {
+		return nil, DecodeErrror{err}
+	}
+	return result.Bugs, nil
+}
+
+// GetHistory returns the history of changes of the given bugs, in the same
+// order of ids. If since is not zeroed, only changes newer than it are
+// returned.
+func (c *Client) GetHistory(ids []int, since time.Time) ([]BugHistory, error) {
+	return c.GetHistoryContext(context.Background(), ids, since)
+}
+
+// GetHistoryContext is GetHistory with a context.Context
+func (c *Client) GetHistoryContext(ctx context.Context, ids []int, since time.Time) ([]BugHistory, error) {
+	params := map[string]string{}
+	if !since.IsZero() {
+		params["new_since"] = since.UTC().Format(searchTimeFormat)
+	}
+	byID := make(map[int]BugHistory, len(ids))
+	for _, chunk := range chunkIds(ids, DefaultChunkSize) {
+		url, err := c.getHistoryURL(chunk, params)
+		if err != nil {
+			return nil, err
+		}
+		body, err := c.fetch(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		histories, err := c.decodeHistory(body)
+		if err != nil {
+			return nil, err
+		}
+		for _, history := range histories {
+			byID[history.ID] = history
+		}
+	}
+
+	result := make([]BugHistory, 0, len(byID))
+	for _, id := range ids {
+		if history, ok := byID[id]; ok {
+			result = append(result, history)
+			delete(byID, id)
+		}
+	}
+	return result, nil
+}
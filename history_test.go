@@ -0,0 +1,82 @@
+package bugzilla_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+const bugsHistoryJson = `
+{
+   "bugs" : [
+      {
+         "alias" : [],
+         "history" : [
+            {
+               "changes" : [
+                  {
+                     "added" : "ASSIGNED",
+                     "field_name" : "status",
+                     "removed" : "NEW"
+                  },
+                  {
+                     "added" : "user1@foobarcorp.example.com",
+                     "field_name" : "assigned_to",
+                     "removed" : "bot1@foobarcorp.example.com"
+                  }
+               ],
+               "when" : "2017-07-03T13:31:23Z",
+               "who" : "user1@foobarcorp.example.com"
+            },
+            {
+               "changes" : [
+                  {
+                     "added" : "1",
+                     "attachment_id" : 766283,
+                     "field_name" : "attachments.isobsolete",
+                     "removed" : "0"
+                  }
+               ],
+               "when" : "2018-04-06T12:50:44Z",
+               "who" : "user1@foobarcorp.example.com"
+            }
+         ],
+         "id" : 1047068
+      }
+   ]
+}
+`
+
+func (cs *clientSuite) TestGetHistory(c *C) {
+	requests := make(chan *http.Request, 1)
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests <- r
+		io.WriteString(w, bugsHistoryJson)
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	since := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	histories, err := bz.GetHistory([]int{1047068}, since)
+	c.Assert(err, IsNil)
+	c.Assert(histories, HasLen, 1)
+	c.Check(histories[0].ID, Equals, 1047068)
+	history := histories[0].History
+	c.Assert(history, HasLen, 2)
+	c.Check(history[0].When, Equals, time.Date(2017, 7, 3, 13, 31, 23, 0, time.UTC))
+	c.Check(history[0].Who, Equals, "user1@foobarcorp.example.com")
+	c.Assert(history[0].Changes, HasLen, 2)
+	c.Check(history[0].Changes[0].FieldName, Equals, "status")
+	c.Check(history[0].Changes[0].Removed, Equals, "NEW")
+	c.Check(history[0].Changes[0].Added, Equals, "ASSIGNED")
+	c.Check(history[0].Changes[0].AttachmentID, IsNil)
+	c.Assert(history[1].Changes[0].AttachmentID, NotNil)
+	c.Check(*history[1].Changes[0].AttachmentID, Equals, 766283)
+
+	r := <-requests
+	c.Check(r.URL.Path, Equals, "/rest/bug/1047068/history")
+	c.Check(r.URL.Query().Get("new_since"), Equals, "2017-01-01T00:00:00Z")
+}
@@ -0,0 +1,88 @@
+package bugzilla
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultConcurrency is the number of bugs loaded at the same time by
+// LoadBugs when LoadOptions.Concurrency is not set
+const DefaultConcurrency = 4
+
+// LoadOptions sets how LoadBugs fetches the bugs
+type LoadOptions struct {
+	WithComments    bool
+	WithAttachments bool
+	// Concurrency is the maximum number of bugs being fetched at the same
+	// time
+	Concurrency int
+	// RequestsPerSecond limits the rate of requests sent to the server by
+	// LoadBugs, zero means no limit. The rate limit set in Config, if any,
+	// still applies.
+	RequestsPerSecond float64
+}
+
+// LoadResult is the outcome of loading one bug: either Bug or Err are set
+type LoadResult struct {
+	ID  int
+	Bug *Bug
+	Err error
+}
+
+// LoadBugs fetches the bugs (and optionally their comments and attachments)
+// in parallel, sending the results as they complete to the returned
+// channel, which is closed once all of them are done. An error on one bug
+// doesn't stop the others from being loaded. When ctx is cancelled the
+// pending bugs are reported with the context error. The channel must be
+// drained.
+//
+// If the Client has a Cacher, it must be safe for concurrent use.
+func (c *Client) LoadBugs(ctx context.Context, ids []int, opts LoadOptions) <-chan LoadResult {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	client := c
+	if opts.RequestsPerSecond > 0 {
+		limited := *c
+		limited.limiter = newRateLimiter(opts.RequestsPerSecond, concurrency, c.limiter)
+		client = &limited
+	}
+
+	jobs := make(chan int)
+	results := make(chan LoadResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				var result LoadResult
+				if err := ctx.Err(); err != nil {
+					result = LoadResult{ID: id, Err: ConnectionError{err}}
+				} else {
+					bug, err := client.GetBugExContext(ctx, id, opts.WithComments, opts.WithAttachments)
+					result = LoadResult{ID: id, Bug: bug, Err: err}
+				}
+				results <- result
+			}
+		}()
+	}
+
+	go func() {
+		seen := make(map[int]bool, len(ids))
+		for _, id := range ids {
+			if !seen[id] {
+				seen[id] = true
+				jobs <- id
+			}
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
@@ -0,0 +1,93 @@
+package bugzilla_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	bugzilla "github.com/bhdn/go-bugzilla-rest"
+	. "gopkg.in/check.v1"
+)
+
+func (cs *clientSuite) TestLoadBugs(c *C) {
+	var mu sync.Mutex
+	active, maxActive := 0, 0
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			active--
+			mu.Unlock()
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		id := strings.TrimPrefix(r.URL.Path, "/rest/bug/")
+		if id == "13" {
+			http.Error(w, `{"code": 101, "error": true, "message": "Bug #13 does not exist."}`, http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"bugs": [{"id": %s}]}`, id)
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	ids := []int{10, 11, 12, 13, 14, 15, 11}
+	results := bz.LoadBugs(context.Background(), ids, bugzilla.LoadOptions{Concurrency: 2})
+	loaded := map[int]bugzilla.LoadResult{}
+	for result := range results {
+		loaded[result.ID] = result
+	}
+	c.Assert(loaded, HasLen, 6)
+	for _, id := range []int{10, 11, 12, 14, 15} {
+		c.Check(loaded[id].Err, IsNil)
+		c.Check(loaded[id].Bug.ID, Equals, id)
+	}
+	c.Check(loaded[13].Bug, IsNil)
+	c.Check(loaded[13].Err, ErrorMatches, ".*does not exist.*")
+	c.Check(maxActive <= 2, Equals, true)
+}
+
+func (cs *clientSuite) TestLoadBugsRate(c *C) {
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/rest/bug/")
+		fmt.Fprintf(w, `{"bugs": [{"id": %s}]}`, id)
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	start := time.Now()
+	opts := bugzilla.LoadOptions{Concurrency: 1, RequestsPerSecond: 50}
+	count := 0
+	for result := range bz.LoadBugs(context.Background(), []int{1, 2, 3, 4, 5, 6}, opts) {
+		c.Check(result.Err, IsNil)
+		count++
+	}
+	c.Check(count, Equals, 6)
+	// One request is allowed right away, the other five wait 20ms each
+	c.Check(time.Since(start) >= 90*time.Millisecond, Equals, true)
+}
+
+func (cs *clientSuite) TestLoadBugsCancelled(c *C) {
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/rest/bug/")
+		fmt.Fprintf(w, `{"bugs": [{"id": %s}]}`, id)
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for result := range bz.LoadBugs(ctx, []int{1, 2, 3}, bugzilla.LoadOptions{}) {
+		c.Check(result.Bug, IsNil)
+		c.Check(result.Err, ErrorMatches, ".*context canceled.*")
+	}
+}
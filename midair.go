@@ -0,0 +1,94 @@
+package bugzilla
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MidAirCollisionError is returned when the server refuses an update
+// because the bug changed after the time given to
+// BugUpdate.IfUnchangedSince (or Changes.DeltaTS, with
+// Changes.ServerDeltaTS). Changes has what happened to the bug since then,
+// and is nil if the history could not be fetched.
+type MidAirCollisionError struct {
+	ID      int
+	DeltaTS time.Time
+	Changes []HistoryEntry
+	// Err is the error returned by the server
+	Err error
+}
+
+func (e *MidAirCollisionError) Error() string {
+	return fmt.Sprintf("bug %d changed %d time(s) since %v: %v", e.ID, len(e.Changes), e.DeltaTS, e.Err)
+}
+
+// Unwrap returns ErrMidAirCollision
+func (e *MidAirCollisionError) Unwrap() error {
+	return ErrMidAirCollision
+}
+
+// isMidAirCollision tells whether err is the server refusing an update
+// because the bug changed after delta_ts. Bugzilla has no dedicated error
+// code for it, so only a 409 Conflict or a message reporting a mid-air
+// collision are taken as one. Other errors mentioning delta_ts or
+// update_token, such as an invalid token, are left as they are.
+func isMidAirCollision(err error) bool {
+	var bzErr BugzillaError
+	if !errors.As(err, &bzErr) {
+		return false
+	}
+	return bzErr.HTTPStatus == http.StatusConflict ||
+		strings.Contains(strings.ToLower(bzErr.Message), "mid-air collision")
+}
+
+// IfUnchangedSince has the server refuse the update, with a
+// *MidAirCollisionError, if the bug was changed after lastChange, usually
+// Bug.LastChangeTime. Unlike Changes.CheckDeltaTS, the check happens on
+// the server, atomically with the update.
+func (u *BugUpdate) IfUnchangedSince(lastChange time.Time) *BugUpdate {
+	return u.setString(&u.upd.DeltaTS, lastChange.UTC().Format(searchTimeFormat))
+}
+
+// WithUpdateToken sends the update token of the bug (Bug.UpdateToken),
+// which some Bugzilla instances require along with IfUnchangedSince
+func (u *BugUpdate) WithUpdateToken(token string) *BugUpdate {
+	return u.setString(&u.upd.UpdateToken, token)
+}
+
+// IfUnchanged is IfUnchangedSince and WithUpdateToken with the values of
+// bug
+func (u *BugUpdate) IfUnchanged(bug *Bug) *BugUpdate {
+	u.IfUnchangedSince(bug.LastChangeTime)
+	if bug.UpdateToken != "" {
+		u.WithUpdateToken(bug.UpdateToken)
+	}
+	return u
+}
+
+// midAirCollision turns err into a *MidAirCollisionError, with the changes
+// made to the bug since the delta_ts sent in up, when err is a collision
+func (c *Client) midAirCollision(ctx context.Context, up *updBug, err error) error {
+	if up.DeltaTS == nil || len(up.Ids) != 1 || !isMidAirCollision(err) {
+		return err
+	}
+	deltaTS, parseErr := time.Parse(searchTimeFormat, *up.DeltaTS)
+	if parseErr != nil {
+		return err
+	}
+	collision := &MidAirCollisionError{ID: up.Ids[0], DeltaTS: deltaTS, Err: err}
+	histories, histErr := c.GetHistoryContext(ctx, up.Ids, deltaTS)
+	if histErr != nil || len(histories) != 1 {
+		return collision
+	}
+	collision.Changes = make([]HistoryEntry, 0)
+	for _, entry := range histories[0].History {
+		if entry.When.After(deltaTS) {
+			collision.Changes = append(collision.Changes, entry)
+		}
+	}
+	return collision
+}
@@ -0,0 +1,160 @@
+package bugzilla_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	bugzilla "github.com/bhdn/go-bugzilla-rest"
+	"github.com/kinbiko/jsonassert"
+	. "gopkg.in/check.v1"
+)
+
+const midAirError = `
+{
+   "documentation" : "https://bugzilla.readthedocs.org/en/5.0/api/",
+   "error" : true,
+   "message" : "Mid-air collision: bug 1047068 has been changed since 2023-04-12T01:02:03Z."
+}
+`
+
+const midAirHistoryJson = `
+{
+   "bugs" : [
+      {
+         "alias" : [],
+         "history" : [
+            {
+               "changes" : [
+                  {
+                     "added" : "P1 - Urgent",
+                     "field_name" : "priority",
+                     "removed" : "P2 - High"
+                  }
+               ],
+               "when" : "2023-04-12T01:02:03Z",
+               "who" : "user1@foobarcorp.example.com"
+            },
+            {
+               "changes" : [
+                  {
+                     "added" : "RESOLVED",
+                     "field_name" : "status",
+                     "removed" : "REOPENED"
+                  }
+               ],
+               "when" : "2023-04-12T02:00:00Z",
+               "who" : "user2@foobarcorp.example.com"
+            }
+         ],
+         "id" : 1047068
+      }
+   ]
+}
+`
+
+// makeMidAirServer refuses all updates as mid-air collisions, sending the
+// bodies of the updates to puts
+func makeMidAirServer(c *C) (*httptest.Server, chan string) {
+	puts := make(chan string, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "PUT":
+			buf := new(strings.Builder)
+			io.Copy(buf, r.Body)
+			puts <- buf.String()
+			http.Error(w, midAirError, http.StatusConflict)
+		case r.URL.Path == "/rest/bug/1047068/history":
+			c.Check(r.URL.Query().Get("new_since"), Equals, "2023-04-12T01:02:03Z")
+			io.WriteString(w, midAirHistoryJson)
+		default:
+			io.WriteString(w, bugsJson)
+		}
+	}))
+	return ts, puts
+}
+
+func (cs *clientSuite) TestApplyUpdateMidAirCollision(c *C) {
+	ts0, puts := makeMidAirServer(c)
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	bug, err := bz.GetBugEx(1047068, false, false)
+	c.Assert(err, IsNil)
+	_, err = bz.ApplyUpdate(1047068, bugzilla.NewUpdate().SetStatus("VERIFIED").IfUnchanged(bug))
+	ja := jsonassert.New(c)
+	ja.Assertf(<-puts, `{
+		"ids": [1047068],
+		"status": "VERIFIED",
+		"delta_ts": "2023-04-12T01:02:03Z",
+		"update_token": "1683306765-PMQ3v1SB5rHQwTPnDeSPrCAmChAk5itzZn7A_WfGgq4"
+	}`)
+
+	c.Assert(err, NotNil)
+	c.Check(errors.Is(err, bugzilla.ErrMidAirCollision), Equals, true)
+	var collision *bugzilla.MidAirCollisionError
+	c.Assert(errors.As(err, &collision), Equals, true)
+	c.Check(collision.ID, Equals, 1047068)
+	c.Check(collision.DeltaTS, Equals, time.Date(2023, 4, 12, 1, 2, 3, 0, time.UTC))
+	c.Assert(collision.Changes, HasLen, 1)
+	c.Check(collision.Changes[0].Who, Equals, "user2@foobarcorp.example.com")
+	c.Check(collision.Changes[0].Changes[0].Added, Equals, "RESOLVED")
+	var bzErr bugzilla.BugzillaError
+	c.Assert(errors.As(collision.Err, &bzErr), Equals, true)
+	c.Check(bzErr.HTTPStatus, Equals, http.StatusConflict)
+}
+
+func (cs *clientSuite) TestUpdateServerDeltaTS(c *C) {
+	ts0, puts := makeMidAirServer(c)
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	changes := bugzilla.Changes{
+		SetStatus:     "VERIFIED",
+		DeltaTS:       time.Date(2023, 4, 12, 1, 2, 3, 0, time.UTC),
+		CheckDeltaTS:  true,
+		ServerDeltaTS: true,
+	}
+	_, err := bz.Update(1047068, changes)
+	ja := jsonassert.New(c)
+	ja.Assertf(<-puts, `{
+		"ids": [1047068],
+		"status": "VERIFIED",
+		"delta_ts": "2023-04-12T01:02:03Z",
+		"update_token": "1683306765-PMQ3v1SB5rHQwTPnDeSPrCAmChAk5itzZn7A_WfGgq4"
+	}`)
+	var collision *bugzilla.MidAirCollisionError
+	c.Assert(errors.As(err, &collision), Equals, true)
+	c.Check(collision.Changes, HasLen, 1)
+}
+
+func (cs *clientSuite) TestApplyUpdateOtherErrorsNotCollisions(c *C) {
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, sampleError, http.StatusBadRequest)
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	update := bugzilla.NewUpdate().SetStatus("VERIFIED").IfUnchangedSince(time.Now())
+	_, err := bz.ApplyUpdate(1047068, update)
+	c.Assert(err, NotNil)
+	c.Check(errors.Is(err, bugzilla.ErrMidAirCollision), Equals, false)
+	c.Check(errors.Is(err, bugzilla.ErrAccessDenied), Equals, true)
+}
+
+func (cs *clientSuite) TestApplyUpdateInvalidTokenNotCollision(c *C) {
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error": true, "message": "The update_token sent is not valid."}`, http.StatusBadRequest)
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	update := bugzilla.NewUpdate().SetStatus("VERIFIED").IfUnchangedSince(time.Now()).WithUpdateToken("bogus")
+	_, err := bz.ApplyUpdate(1047068, update)
+	c.Assert(err, NotNil)
+	c.Check(errors.Is(err, bugzilla.ErrMidAirCollision), Equals, false)
+	c.Check(err, FitsTypeOf, bugzilla.BugzillaError{})
+}
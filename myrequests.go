@@ -0,0 +1,123 @@
+package bugzilla
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PendingFlag is a flag with status "?", along with where it is set
+type PendingFlag struct {
+	Flag
+	BugID      int
+	BugSummary string
+	// AttachmentID is zeroed for flags set on the bug itself
+	AttachmentID int
+	// Age is the time since the flag was created
+	Age time.Duration
+}
+
+// PendingRequests has the pending flags of a user, as returned by
+// MyRequests, grouped by flag name and sorted from the oldest to the
+// newest
+type PendingRequests struct {
+	Email string
+	// Requested has the flags requested from the user
+	Requested map[string][]PendingFlag
+	// Set has the flags the user set that are still pending
+	Set map[string][]PendingFlag
+}
+
+func (p *PendingRequests) add(bug *Bug, attachmentID int, flags []Flag, now time.Time) {
+	for _, flag := range flags {
+		if flag.Status != FlagStatusRequested {
+			continue
+		}
+		pending := PendingFlag{
+			Flag:         flag,
+			BugID:        bug.ID,
+			BugSummary:   bug.Summary,
+			AttachmentID: attachmentID,
+			Age:          now.Sub(flag.CreationDate),
+		}
+		if strings.EqualFold(flag.Requestee, p.Email) {
+			p.Requested[flag.Name] = append(p.Requested[flag.Name], pending)
+		}
+		if strings.EqualFold(flag.Setter, p.Email) {
+			p.Set[flag.Name] = append(p.Set[flag.Name], pending)
+		}
+	}
+}
+
+func sortPendingFlags(groups map[string][]PendingFlag) {
+	for _, flags := range groups {
+		sort.SliceStable(flags, func(i, j int) bool {
+			return flags[i].CreationDate.Before(flags[j].CreationDate)
+		})
+	}
+}
+
+// flaggedBugs returns the bugs having flags with the user in field, which
+// is either "requestees.login_name" or "setters.login_name"
+func (c *Client) flaggedBugs(ctx context.Context, field string, email string, bugs map[int]*Bug, ids *[]int) error {
+	q := NewSearchQuery().Chart(field, "equals", email).Set("include_fields", "id", "summary", "flags")
+	it := c.SearchIterContext(ctx, q, 0)
+	for it.Next() {
+		bug := it.Bug()
+		if _, ok := bugs[bug.ID]; !ok {
+			bugs[bug.ID] = bug
+			*ids = append(*ids, bug.ID)
+		}
+	}
+	return it.Err()
+}
+
+// MyRequests returns the flags waiting on the user email, on bugs and on
+// their attachments, and the flags the user set that are still waiting on
+// someone. email defaults to the address in Config.
+func (c *Client) MyRequests(email string) (*PendingRequests, error) {
+	return c.MyRequestsContext(context.Background(), email)
+}
+
+// MyRequestsContext is MyRequests with a context.Context
+func (c *Client) MyRequestsContext(ctx context.Context, email string) (*PendingRequests, error) {
+	if email == "" {
+		var err error
+		if email, err = c.Config.emailAddress(); err != nil {
+			return nil, err
+		}
+	}
+
+	bugs := make(map[int]*Bug)
+	ids := make([]int, 0)
+	for _, field := range []string{"requestees.login_name", "setters.login_name"} {
+		if err := c.flaggedBugs(ctx, field, email, bugs, &ids); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	requests := &PendingRequests{
+		Email:     email,
+		Requested: make(map[string][]PendingFlag),
+		Set:       make(map[string][]PendingFlag),
+	}
+	for _, id := range ids {
+		requests.add(bugs[id], 0, bugs[id].Flags, now)
+	}
+	for _, chunk := range chunkIds(ids, DefaultChunkSize) {
+		attachments, err := c.GetAttachmentsInfoContext(ctx, chunk)
+		if err != nil {
+			return nil, err
+		}
+		for i := range attachments {
+			if bug, ok := bugs[attachments[i].BugId]; ok {
+				requests.add(bug, attachments[i].ID, attachments[i].Flags, now)
+			}
+		}
+	}
+	sortPendingFlags(requests.Requested)
+	sortPendingFlags(requests.Set)
+	return requests, nil
+}
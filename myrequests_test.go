@@ -0,0 +1,97 @@
+package bugzilla_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	bugzilla "github.com/bhdn/go-bugzilla-rest"
+	. "gopkg.in/check.v1"
+)
+
+func flagJson(id int, name, status, setter, requestee string, created time.Time) string {
+	return fmt.Sprintf(`{"id": %d, "name": %q, "status": %q, "setter": %q, "requestee": %q, "type_id": 1, "creation_date": %q, "modification_date": %q}`,
+		id, name, status, setter, requestee, created.Format(time.RFC3339), created.Format(time.RFC3339))
+}
+
+func (cs *clientSuite) TestMyRequests(c *C) {
+	me := "user1@foobarcorp.example.com"
+	other := "user2@foobarcorp.example.com"
+	now := time.Now().UTC().Truncate(time.Second)
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/rest/bug":
+			c.Check(query.Get("v1"), Equals, me)
+			if query.Get("f2") == "bug_id" {
+				// No more pages after the first one
+				fmt.Fprint(w, `{"bugs": []}`)
+				return
+			}
+			switch query.Get("f1") {
+			case "requestees.login_name":
+				fmt.Fprintf(w, `{"bugs": [
+					{"id": 10, "summary": "Crash", "flags": [%s, %s, %s]},
+					{"id": 20, "summary": "Patch", "flags": []}
+				]}`,
+					flagJson(1, "needinfo", "?", other, me, now.Add(-time.Hour)),
+					flagJson(2, "needinfo", "?", other, me, now.Add(-48*time.Hour)),
+					flagJson(3, "qe_ack", "+", other, me, now.Add(-time.Hour)))
+			case "setters.login_name":
+				fmt.Fprintf(w, `{"bugs": [
+					{"id": 20, "summary": "Patch", "flags": []},
+					{"id": 30, "summary": "Docs", "flags": [%s]}
+				]}`,
+					flagJson(4, "needinfo", "?", me, other, now.Add(-2*time.Hour)))
+			default:
+				c.Errorf("unexpected search: %v", query)
+			}
+		case "/rest/bug/10/attachment":
+			c.Check(query["ids"], DeepEquals, []string{"10", "20", "30"})
+			fmt.Fprintf(w, `{"attachments": {}, "bugs": {
+				"10": [],
+				"20": [{"id": 200, "bug_id": 20, "flags": [%s]}],
+				"30": [{"id": 300, "bug_id": 30, "flags": [%s]}]
+			}}`,
+				flagJson(5, "review", "?", other, me, now.Add(-3*time.Hour)),
+				flagJson(6, "review", "-", me, other, now.Add(-3*time.Hour)))
+		default:
+			c.Errorf("unexpected request: %v", r.URL)
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts0.Close()
+	config := bugzilla.Config{BaseURL: ts0.URL, ApiKey: "xxxxxx", Username: me}
+	bz, err := bugzilla.New(config)
+	c.Assert(err, IsNil)
+
+	requests, err := bz.MyRequests("")
+	c.Assert(err, IsNil)
+	c.Check(requests.Email, Equals, me)
+
+	c.Assert(requests.Requested, HasLen, 2)
+	needinfos := requests.Requested["needinfo"]
+	c.Assert(needinfos, HasLen, 2)
+	c.Check(needinfos[0].ID, Equals, 2)
+	c.Check(needinfos[0].BugID, Equals, 10)
+	c.Check(needinfos[0].BugSummary, Equals, "Crash")
+	c.Check(needinfos[0].AttachmentID, Equals, 0)
+	c.Check(needinfos[0].Age >= 48*time.Hour, Equals, true)
+	c.Check(needinfos[1].ID, Equals, 1)
+	reviews := requests.Requested["review"]
+	c.Assert(reviews, HasLen, 1)
+	c.Check(reviews[0].BugID, Equals, 20)
+	c.Check(reviews[0].AttachmentID, Equals, 200)
+
+	c.Assert(requests.Set, HasLen, 1)
+	c.Assert(requests.Set["needinfo"], HasLen, 1)
+	c.Check(requests.Set["needinfo"][0].Requestee, Equals, other)
+	c.Check(requests.Set["needinfo"][0].BugID, Equals, 30)
+}
+
+func (cs *clientSuite) TestMyRequestsNoEmail(c *C) {
+	bz := makeClient("http://bz.foobarcorp.example.com")
+	_, err := bz.MyRequests("")
+	c.Check(err, ErrorMatches, ".*doesn't look like an email address.*")
+}
@@ -0,0 +1,117 @@
+package bugzilla
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimitStats reports how requests have been delayed by the rate limiter
+// set in Config
+type RateLimitStats struct {
+	Requests  int64         // requests that went through the limiter
+	Delayed   int64         // requests that had to wait for a token
+	TotalWait time.Duration // time spent waiting for tokens
+	MaxWait   time.Duration // longest time a request waited
+}
+
+// rateLimiter is a token bucket: tokens are added at rate per second up to
+// burst and each request takes one of them. Requests also have to go
+// through the parent limiter, if any.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	stats  RateLimitStats
+	parent *rateLimiter
+}
+
+func newRateLimiter(rate float64, burst int, parent *rateLimiter) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+		parent: parent,
+	}
+}
+
+// reserve takes a token and returns how long the caller has to wait
+// before using it
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel gives back a token taken by reserve that was not used
+func (l *rateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+}
+
+func (l *rateLimiter) record(waited time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stats.Requests++
+	if waited > 0 {
+		l.stats.Delayed++
+		l.stats.TotalWait += waited
+		if waited > l.stats.MaxWait {
+			l.stats.MaxWait = waited
+		}
+	}
+}
+
+// wait blocks until a token is available or ctx is done
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	var waited time.Duration
+	if delay := l.reserve(); delay > 0 {
+		start := time.Now()
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			l.cancel()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		waited = time.Since(start)
+	}
+	l.record(waited)
+	return l.parent.wait(ctx)
+}
+
+func (l *rateLimiter) getStats() RateLimitStats {
+	if l == nil {
+		return RateLimitStats{}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
+
+// RateLimitStats returns the statistics of the rate limiter set in Config.
+// It is zeroed when there is no rate limit.
+func (c *Client) RateLimitStats() RateLimitStats {
+	return c.limiter.getStats()
+}
@@ -0,0 +1,88 @@
+package bugzilla_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	bugzilla "github.com/bhdn/go-bugzilla-rest"
+	. "gopkg.in/check.v1"
+)
+
+func makeClientWithRateLimit(url string, rate float64, burst int) *bugzilla.Client {
+	config := bugzilla.Config{BaseURL: url, ApiKey: "xxxxxx", RateLimit: rate, RateBurst: burst}
+	bz, _ := bugzilla.New(config)
+	return bz
+}
+
+func makeEchoBugServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/rest/bug/")
+		fmt.Fprintf(w, `{"bugs": [{"id": %s}]}`, id)
+	}))
+}
+
+func (cs *clientSuite) TestRateLimit(c *C) {
+	ts0 := makeEchoBugServer()
+	defer ts0.Close()
+	bz := makeClientWithRateLimit(ts0.URL, 50, 2)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 1; i <= 6; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			_, err := bz.GetBugEx(id, false, false)
+			c.Check(err, IsNil)
+		}(i)
+	}
+	wg.Wait()
+
+	// Two requests go right away, the other four wait 20ms each
+	c.Check(time.Since(start) >= 70*time.Millisecond, Equals, true)
+	stats := bz.RateLimitStats()
+	c.Check(stats.Requests, Equals, int64(6))
+	c.Check(stats.Delayed, Equals, int64(4))
+	c.Check(stats.TotalWait > 0, Equals, true)
+	c.Check(stats.MaxWait >= 70*time.Millisecond, Equals, true)
+}
+
+func (cs *clientSuite) TestRateLimitCancelled(c *C) {
+	ts0 := makeEchoBugServer()
+	defer ts0.Close()
+	bz := makeClientWithRateLimit(ts0.URL, 0.1, 1)
+
+	_, err := bz.GetBugEx(1, false, false)
+	c.Assert(err, IsNil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = bz.GetBugExContext(ctx, 2, false, false)
+	c.Assert(err, ErrorMatches, ".*context deadline exceeded.*")
+	c.Check(bz.RateLimitStats().Requests, Equals, int64(1))
+}
+
+func (cs *clientSuite) TestRateLimitDownloadAttachment(c *C) {
+	ts0 := makeEchoBugServer()
+	defer ts0.Close()
+	bz := makeClientWithRateLimit(ts0.URL, 50, 1)
+
+	for i := 1; i <= 2; i++ {
+		_, reader, err := bz.DownloadAttachment(766288)
+		c.Assert(err, IsNil)
+		reader.Close()
+	}
+	stats := bz.RateLimitStats()
+	c.Check(stats.Requests, Equals, int64(2))
+	c.Check(stats.Delayed, Equals, int64(1))
+}
+
+func (cs *clientSuite) TestNoRateLimitStats(c *C) {
+	bz := makeClient("http://foobar.com/")
+	c.Check(bz.RateLimitStats(), Equals, bugzilla.RateLimitStats{})
+}
@@ -0,0 +1,158 @@
+package bugzilla
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy sets how requests are retried after transient failures:
+// connection errors and the 429, 502, 503 and 504 statuses. Only GET
+// requests are retried unless RetryNonIdempotent is set. The zero value
+// disables retries.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first
+	// one
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, doubled at each
+	// following attempt. Defaults to DefaultRetryBaseDelay.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between attempts, except when the server
+	// asks for a longer one with Retry-After. Defaults to
+	// DefaultRetryMaxDelay.
+	MaxDelay time.Duration
+	// RetryNonIdempotent allows retrying PUT and POST requests, which may
+	// apply the same change twice
+	RetryNonIdempotent bool
+}
+
+// Defaults for the zeroed fields of RetryPolicy
+const (
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	DefaultRetryMaxDelay  = 30 * time.Second
+)
+
+func (p *RetryPolicy) allows(method string, attempt int) bool {
+	if attempt >= p.MaxAttempts {
+		return false
+	}
+	return method == http.MethodGet || p.RetryNonIdempotent
+}
+
+func (p *RetryPolicy) isTransient(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		// Errors caused by the context being done are not transient
+		return ctx.Err() == nil
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses the Retry-After header, either in seconds or as an
+// HTTP date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		delay := time.Until(when)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// delay returns how long to wait before the next attempt, with the
+// exponential backoff randomized between half and the full value
+func (p *RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if delay, ok := retryAfter(resp); ok {
+		return delay
+	}
+	base := p.BaseDelay
+	if base <= 0 {
+		base = DefaultRetryBaseDelay
+	}
+	max := p.MaxDelay
+	if max <= 0 {
+		max = DefaultRetryMaxDelay
+	}
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// do performs a request, retrying it according to Config.Retry, and then
+// collects the response
+func (c *Client) do(ctx context.Context, method string, url string, body []byte) ([]byte, error) {
+	resp, err := c.send(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	return c.collect(resp, nil)
+}
+
+// send performs a request, respecting the rate limit and retrying it
+// according to Config.Retry, and returns the last response with its body
+// still to be read
+func (c *Client) send(ctx context.Context, method string, url string, body []byte) (*http.Response, error) {
+	policy := &c.Config.Retry
+	for attempt := 1; ; attempt++ {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, ConnectionError{err}
+		}
+		request, err := http.NewRequestWithContext(ctx, method, url, reader)
+		if err != nil {
+			return nil, RequestError{err}
+		}
+		resp, err := c.seriousClient.Do(request)
+		if !policy.allows(method, attempt) || !policy.isTransient(ctx, resp, err) {
+			if err != nil {
+				if resp != nil {
+					resp.Body.Close()
+				}
+				return nil, ConnectionError{err}
+			}
+			return resp, nil
+		}
+		delay := policy.delay(attempt, resp)
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ConnectionError{ctx.Err()}
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package bugzilla_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	bugzilla "github.com/bhdn/go-bugzilla-rest"
+	. "gopkg.in/check.v1"
+)
+
+func makeClientWithRetry(url string, retry bugzilla.RetryPolicy) *bugzilla.Client {
+	config := bugzilla.Config{BaseURL: url, ApiKey: "xxxxxx", Retry: retry}
+	bz, _ := bugzilla.New(config)
+	return bz
+}
+
+// makeFlakyServer fails the first failures requests with status and then
+// answers with response
+func makeFlakyServer(failures int, status int, response string) (*httptest.Server, *int) {
+	requests := 0
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= failures {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		io.WriteString(w, response)
+	}))
+	return ts0, &requests
+}
+
+func (cs *clientSuite) TestRetryGet(c *C) {
+	ts0, requests := makeFlakyServer(2, http.StatusServiceUnavailable, bugsJson)
+	defer ts0.Close()
+	bz := makeClientWithRetry(ts0.URL, bugzilla.RetryPolicy{MaxAttempts: 3})
+
+	bug, err := bz.GetBugEx(1047068, false, false)
+	c.Assert(err, IsNil)
+	c.Check(bug.ID, Equals, 1047068)
+	c.Check(*requests, Equals, 3)
+}
+
+func (cs *clientSuite) TestRetryDownloadAttachment(c *C) {
+	ts0, requests := makeFlakyServer(1, http.StatusServiceUnavailable, singleAttachmentJson)
+	defer ts0.Close()
+	bz := makeClientWithRetry(ts0.URL, bugzilla.RetryPolicy{MaxAttempts: 2})
+
+	_, reader, err := bz.DownloadAttachment(766288)
+	c.Assert(err, IsNil)
+	defer reader.Close()
+	c.Check(*requests, Equals, 2)
+}
+
+func (cs *clientSuite) TestRetryGivesUp(c *C) {
+	ts0, requests := makeFlakyServer(5, http.StatusBadGateway, bugsJson)
+	defer ts0.Close()
+	bz := makeClientWithRetry(ts0.URL, bugzilla.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	_, err := bz.GetBugEx(1047068, false, false)
+	c.Assert(err, FitsTypeOf, bugzilla.BugzillaError{})
+	c.Check(*requests, Equals, 3)
+}
+
+func (cs *clientSuite) TestRetryNotTransient(c *C) {
+	ts0, requests := makeFlakyServer(5, http.StatusNotFound, bugsJson)
+	defer ts0.Close()
+	bz := makeClientWithRetry(ts0.URL, bugzilla.RetryPolicy{MaxAttempts: 3})
+
+	_, err := bz.GetBugEx(1047068, false, false)
+	c.Assert(err, NotNil)
+	c.Check(*requests, Equals, 1)
+}
+
+func (cs *clientSuite) TestRetryPost(c *C) {
+	ts0, requests := makeFlakyServer(1, http.StatusTooManyRequests, `{"id": 1050001}`)
+	defer ts0.Close()
+	bz := makeClientWithRetry(ts0.URL, bugzilla.RetryPolicy{MaxAttempts: 3})
+
+	_, err := bz.CreateBug(bugzilla.NewBug{Summary: "It crashed"})
+	c.Assert(err, NotNil)
+	c.Check(*requests, Equals, 1)
+
+	bz.Config.Retry.RetryNonIdempotent = true
+	id, err := bz.CreateBug(bugzilla.NewBug{Summary: "It crashed"})
+	c.Assert(err, IsNil)
+	c.Check(id, Equals, 1050001)
+	c.Check(*requests, Equals, 2)
+}
+
+func (cs *clientSuite) TestRetryConnectionClosed(c *C) {
+	requests := 0
+	var ts0 *httptest.Server
+	ts0 = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			ts0.CloseClientConnections()
+			return
+		}
+		io.WriteString(w, bugsJson)
+	}))
+	defer ts0.Close()
+	bz := makeClientWithRetry(ts0.URL, bugzilla.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond})
+
+	bug, err := bz.GetBugEx(1047068, false, false)
+	c.Assert(err, IsNil)
+	c.Check(bug.ID, Equals, 1047068)
+	c.Check(requests, Equals, 2)
+}
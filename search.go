@@ -0,0 +1,280 @@
+package bugzilla
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// searchTimeFormat is the timestamp format accepted by Bugzilla in search
+// parameters
+const searchTimeFormat = "2006-01-02T15:04:05Z"
+
+// Chart is one criterion of a boolean chart (the f1/o1/v1 parameters of
+// Bugzilla's advanced search). Operator is one of the operators Bugzilla
+// accepts, such as "equals", "substring", "greaterthaneq" or "changedafter".
+type Chart struct {
+	Field    string
+	Operator string
+	Value    string
+	Negate   bool
+}
+
+// SearchQuery builds the parameters for Search(). All criteria are ANDed
+// together and the methods can be chained:
+//
+//	q := NewSearchQuery().Product("Frobnicator").Status("NEW", "ASSIGNED")
+type SearchQuery struct {
+	values url.Values
+	charts []Chart
+}
+
+// NewSearchQuery returns an empty *SearchQuery
+func NewSearchQuery() *SearchQuery {
+	return &SearchQuery{values: url.Values{}}
+}
+
+func (q *SearchQuery) addValues(key string, values []string) *SearchQuery {
+	for _, v := range values {
+		q.values.Add(key, v)
+	}
+	return q
+}
+
+// Product restricts the search to bugs in any of the given products
+func (q *SearchQuery) Product(names ...string) *SearchQuery {
+	return q.addValues("product", names)
+}
+
+// Component restricts the search to bugs in any of the given components
+func (q *SearchQuery) Component(names ...string) *SearchQuery {
+	return q.addValues("component", names)
+}
+
+// Status restricts the search to bugs in any of the given statuses
+func (q *SearchQuery) Status(statuses ...string) *SearchQuery {
+	return q.addValues("status", statuses)
+}
+
+// AssignedTo restricts the search to bugs assigned to any of the given
+// users
+func (q *SearchQuery) AssignedTo(emails ...string) *SearchQuery {
+	return q.addValues("assigned_to", emails)
+}
+
+// Keywords restricts the search to bugs having all the given keywords
+func (q *SearchQuery) Keywords(keywords ...string) *SearchQuery {
+	for _, keyword := range keywords {
+		q.Chart("keywords", "anywords", keyword)
+	}
+	return q
+}
+
+// Whiteboard restricts the search to bugs whose whiteboard contains all the
+// given substrings
+func (q *SearchQuery) Whiteboard(substrings ...string) *SearchQuery {
+	for _, substring := range substrings {
+		q.Chart("status_whiteboard", "substring", substring)
+	}
+	return q
+}
+
+func (q *SearchQuery) timeRange(field string, from, to time.Time) *SearchQuery {
+	if !from.IsZero() {
+		q.Chart(field, "greaterthaneq", from.UTC().Format(searchTimeFormat))
+	}
+	if !to.IsZero() {
+		q.Chart(field, "lessthan", to.UTC().Format(searchTimeFormat))
+	}
+	return q
+}
+
+// CreatedBetween restricts the search to bugs created in [from, to). Either
+// of them can be zeroed to leave the range open.
+func (q *SearchQuery) CreatedBetween(from, to time.Time) *SearchQuery {
+	return q.timeRange("creation_ts", from, to)
+}
+
+// ChangedBetween restricts the search to bugs last changed in [from, to).
+// Either of them can be zeroed to leave the range open.
+func (q *SearchQuery) ChangedBetween(from, to time.Time) *SearchQuery {
+	return q.timeRange("delta_ts", from, to)
+}
+
+// Chart adds a boolean chart criterion
+func (q *SearchQuery) Chart(field, operator, value string) *SearchQuery {
+	q.charts = append(q.charts, Chart{Field: field, Operator: operator, Value: value})
+	return q
+}
+
+// NotChart adds a negated boolean chart criterion
+func (q *SearchQuery) NotChart(field, operator, value string) *SearchQuery {
+	q.charts = append(q.charts, Chart{Field: field, Operator: operator, Value: value, Negate: true})
+	return q
+}
+
+// Set adds any other raw search parameter understood by Bugzilla
+func (q *SearchQuery) Set(key string, values ...string) *SearchQuery {
+	q.values.Del(key)
+	return q.addValues(key, values)
+}
+
+// Values returns the URL parameters for the query
+func (q *SearchQuery) Values() url.Values {
+	values := q.clone().values
+	for i, chart := range q.charts {
+		n := i + 1
+		values.Set(fmt.Sprintf("f%d", n), chart.Field)
+		values.Set(fmt.Sprintf("o%d", n), chart.Operator)
+		values.Set(fmt.Sprintf("v%d", n), chart.Value)
+		if chart.Negate {
+			values.Set(fmt.Sprintf("n%d", n), "1")
+		}
+	}
+	return values
+}
+
+func (c *Client) getSearchURL(query *SearchQuery) (string, error) {
+	values := query.Values()
+	return c.makeURL("/rest/bug", &values)
+}
+
+// Search returns the bugs matching query, without comments or attachments
+func (c *Client) Search(query *SearchQuery) ([]Bug, error) {
+	return c.SearchContext(context.Background(), query)
+}
+
+// SearchContext is Search with a context.Context
+func (c *Client) SearchContext(ctx context.Context, query *SearchQuery) ([]Bug, error) {
+	url, err := c.getSearchURL(query)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return c.decodeBugs(body)
+}
+
+// Limit sets the maximum number of bugs returned by the search
+func (q *SearchQuery) Limit(n int) *SearchQuery {
+	return q.Set("limit", fmt.Sprintf("%d", n))
+}
+
+// Offset sets the number of bugs to skip from the start of the results
+func (q *SearchQuery) Offset(n int) *SearchQuery {
+	return q.Set("offset", fmt.Sprintf("%d", n))
+}
+
+// Order sets the sort order of the results, as in "bug_id" or
+// "changeddate DESC"
+func (q *SearchQuery) Order(fields ...string) *SearchQuery {
+	return q.Set("order", strings.Join(fields, ","))
+}
+
+func (q *SearchQuery) clone() *SearchQuery {
+	values := url.Values{}
+	for k, vs := range q.values {
+		values[k] = append([]string(nil), vs...)
+	}
+	return &SearchQuery{values: values, charts: append([]Chart(nil), q.charts...)}
+}
+
+// DefaultPageSize is the number of bugs fetched per request by the
+// iterator returned by SearchIter when no page size is given
+const DefaultPageSize = 500
+
+// BugIterator walks through the results of a search one page at a time:
+//
+//	it := client.SearchIter(query, 0)
+//	for it.Next() {
+//		bug := it.Bug()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+//
+// Pages are ordered by bug ID and each page starts after the last ID seen,
+// instead of using a plain offset, so bugs that change while the iteration
+// is in progress don't cause other bugs to be skipped or repeated.
+type BugIterator struct {
+	ctx      context.Context
+	client   *Client
+	query    *SearchQuery
+	pageSize int
+	lastID   int
+	page     []Bug
+	pos      int
+	err      error
+}
+
+// SearchIter returns a *BugIterator for query. Limit, offset and order set
+// in the query are ignored. pageSize <= 0 uses DefaultPageSize.
+func (c *Client) SearchIter(query *SearchQuery, pageSize int) *BugIterator {
+	return c.SearchIterContext(context.Background(), query, pageSize)
+}
+
+// SearchIterContext is SearchIter with a context.Context used for fetching
+// all the pages
+func (c *Client) SearchIterContext(ctx context.Context, query *SearchQuery, pageSize int) *BugIterator {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	q := query.clone()
+	q.values.Del("offset")
+	return &BugIterator{ctx: ctx, client: c, query: q, pageSize: pageSize, pos: -1}
+}
+
+func (it *BugIterator) fetchPage() error {
+	q := it.query.clone()
+	q.Limit(it.pageSize).Order("bug_id")
+	if it.lastID > 0 {
+		q.Chart("bug_id", "greaterthan", fmt.Sprintf("%d", it.lastID))
+	}
+	bugs, err := it.client.SearchContext(it.ctx, q)
+	if err != nil {
+		return err
+	}
+	it.page = bugs
+	it.pos = -1
+	return nil
+}
+
+// Next advances to the next bug, fetching a new page when needed. It
+// returns false when there are no more bugs or an error happened.
+func (it *BugIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.pos+1 >= len(it.page) {
+		if it.err = it.fetchPage(); it.err != nil {
+			return false
+		}
+		// Bugzilla may return fewer bugs than asked for, when the page size
+		// is above its max_search_results, so only an empty page means the
+		// end of the results
+		if len(it.page) == 0 {
+			return false
+		}
+	}
+	it.pos++
+	it.lastID = it.page[it.pos].ID
+	return true
+}
+
+// Bug returns the current bug
+func (it *BugIterator) Bug() *Bug {
+	if it.pos < 0 || it.pos >= len(it.page) {
+		return nil
+	}
+	return &it.page[it.pos]
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *BugIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,143 @@
+package bugzilla_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+
+	bugzilla "github.com/bhdn/go-bugzilla-rest"
+	. "gopkg.in/check.v1"
+)
+
+func (cs *clientSuite) TestSearchQueryValues(c *C) {
+	from := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	q := bugzilla.NewSearchQuery().
+		Product("Enterprise Frobnicator 9000.1").
+		Component("Basesystem").
+		Status("NEW", "ASSIGNED").
+		AssignedTo("user1@foobarcorp.example.com").
+		Keywords("TRETA").
+		Whiteboard("openTreta").
+		ChangedBetween(from, time.Time{}).
+		NotChart("cc", "substring", "user2")
+
+	values := q.Values()
+	c.Check(values["product"], DeepEquals, []string{"Enterprise Frobnicator 9000.1"})
+	c.Check(values["component"], DeepEquals, []string{"Basesystem"})
+	c.Check(values["status"], DeepEquals, []string{"NEW", "ASSIGNED"})
+	c.Check(values["assigned_to"], DeepEquals, []string{"user1@foobarcorp.example.com"})
+	c.Check(values.Get("f1"), Equals, "keywords")
+	c.Check(values.Get("o1"), Equals, "anywords")
+	c.Check(values.Get("v1"), Equals, "TRETA")
+	c.Check(values.Get("f2"), Equals, "status_whiteboard")
+	c.Check(values.Get("o2"), Equals, "substring")
+	c.Check(values.Get("v2"), Equals, "openTreta")
+	c.Check(values.Get("f3"), Equals, "delta_ts")
+	c.Check(values.Get("o3"), Equals, "greaterthaneq")
+	c.Check(values.Get("v3"), Equals, "2023-01-02T03:04:05Z")
+	c.Check(values.Get("f4"), Equals, "cc")
+	c.Check(values.Get("n4"), Equals, "1")
+	c.Check(values.Get("n1"), Equals, "")
+}
+
+func (cs *clientSuite) TestSearch(c *C) {
+	queries := make(chan *http.Request, 1)
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries <- r
+		io.WriteString(w, bugsJson)
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	bugs, err := bz.Search(bugzilla.NewSearchQuery().Product("Enterprise Frobnicator 9000.1").Status("REOPENED"))
+	c.Assert(err, IsNil)
+	c.Assert(bugs, HasLen, 1)
+	c.Check(bugs[0].ID, Equals, 1047068)
+
+	r := <-queries
+	c.Check(r.URL.Path, Equals, "/rest/bug")
+	c.Check(r.URL.Query().Get("product"), Equals, "Enterprise Frobnicator 9000.1")
+	c.Check(r.URL.Query().Get("status"), Equals, "REOPENED")
+	c.Check(r.URL.Query().Get("Bugzilla_api_key"), Equals, "xxxxxx")
+}
+
+// makeSearchIterServer serves the bugs in ids to the requests of a
+// BugIterator with the given page size, returning at most max bugs per
+// request, as Bugzilla does with max_search_results
+func makeSearchIterServer(c *C, ids []int, pageSize int, max int) (*httptest.Server, *int) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		query := r.URL.Query()
+		c.Check(query.Get("order"), Equals, "bug_id")
+		c.Check(query.Get("limit"), Equals, strconv.Itoa(pageSize))
+		c.Check(query.Get("offset"), Equals, "")
+		after := 0
+		if query.Get("f2") == "bug_id" {
+			c.Check(query.Get("o2"), Equals, "greaterthan")
+			after, _ = strconv.Atoi(query.Get("v2"))
+		}
+		bugs := []string{}
+		for _, id := range ids {
+			if id > after && len(bugs) < max {
+				bugs = append(bugs, fmt.Sprintf(`{"id": %d}`, id))
+			}
+		}
+		fmt.Fprintf(w, `{"bugs": [%s]}`, strings.Join(bugs, ","))
+	}))
+	return ts, &requests
+}
+
+func (cs *clientSuite) TestSearchIter(c *C) {
+	ids := []int{3, 5, 8, 13, 21}
+	ts0, requests := makeSearchIterServer(c, ids, 2, 2)
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	q := bugzilla.NewSearchQuery().Whiteboard("openTreta").Offset(10)
+	it := bz.SearchIter(q, 2)
+	found := []int{}
+	for it.Next() {
+		found = append(found, it.Bug().ID)
+	}
+	c.Assert(it.Err(), IsNil)
+	c.Check(found, DeepEquals, ids)
+	c.Check(*requests, Equals, 4)
+	c.Check(it.Bug(), IsNil)
+
+	// The query passed is left untouched
+	c.Check(q.Values().Get("offset"), Equals, "10")
+	c.Check(q.Values().Get("f2"), Equals, "")
+}
+
+func (cs *clientSuite) TestSearchIterServerLimit(c *C) {
+	ids := []int{3, 5, 8, 13, 21}
+	ts0, requests := makeSearchIterServer(c, ids, 500, 2)
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	it := bz.SearchIter(bugzilla.NewSearchQuery().Whiteboard("openTreta"), 0)
+	found := []int{}
+	for it.Next() {
+		found = append(found, it.Bug().ID)
+	}
+	c.Assert(it.Err(), IsNil)
+	c.Check(found, DeepEquals, ids)
+	c.Check(*requests, Equals, 4)
+}
+
+func (cs *clientSuite) TestSearchIterError(c *C) {
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, sampleError, http.StatusBadRequest)
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	it := bz.SearchIter(bugzilla.NewSearchQuery(), 0)
+	c.Check(it.Next(), Equals, false)
+	c.Check(it.Err(), ErrorMatches, ".*You are not authorized.*")
+}
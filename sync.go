@@ -0,0 +1,180 @@
+package bugzilla
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// DefaultSyncOverlap is how far before the last high-water mark Sync looks
+// for changes when SyncOptions.Overlap is not set
+const DefaultSyncOverlap = 5 * time.Minute
+
+// SyncSink receives the bugs found by Sync. history has only the changes
+// in the sync window and is nil unless SyncOptions.WithHistory is set.
+type SyncSink interface {
+	PutBug(bug *Bug, history []HistoryEntry) error
+}
+
+// SyncSinkFunc adapts a function to the SyncSink interface
+type SyncSinkFunc func(bug *Bug, history []HistoryEntry) error
+
+// PutBug calls f(bug, history)
+func (f SyncSinkFunc) PutBug(bug *Bug, history []HistoryEntry) error {
+	return f(bug, history)
+}
+
+// SyncCursor is where a Sync run stopped, to be persisted (it can be
+// encoded as JSON) and passed to the next run. The zero value syncs all
+// bugs.
+type SyncCursor struct {
+	// HighWater is the newest last_change_time seen, as reported by the
+	// server
+	HighWater time.Time `json:"high_water"`
+	// Seen has the bugs (and their last_change_time) already emitted
+	// inside the overlap window, so they are not emitted again
+	Seen map[int]time.Time `json:"seen,omitempty"`
+}
+
+// SyncOptions sets what Sync looks for. Bugs matching any of Products or
+// Queries are synced; when both are empty, all bugs are.
+type SyncOptions struct {
+	Products []string
+	Queries  []*SearchQuery
+
+	WithComments    bool
+	WithAttachments bool
+	WithHistory     bool
+
+	// Overlap is subtracted from the high-water mark when searching, to
+	// cope with clock skew and changes committed out of order on the
+	// server
+	Overlap time.Duration
+	// PageSize is the page size used for searching and the number of
+	// bugs fetched in each request
+	PageSize int
+}
+
+func (o *SyncOptions) queries() []*SearchQuery {
+	queries := make([]*SearchQuery, 0, len(o.Queries)+1)
+	if len(o.Products) > 0 {
+		queries = append(queries, NewSearchQuery().Product(o.Products...))
+	}
+	queries = append(queries, o.Queries...)
+	if len(queries) == 0 {
+		queries = append(queries, NewSearchQuery())
+	}
+	return queries
+}
+
+// changedBugs returns the IDs of the bugs changed since the given time and
+// not emitted yet, in the order they were found, and their
+// last_change_time
+func (c *Client) changedBugs(ctx context.Context, opts *SyncOptions, since time.Time, seen map[int]time.Time) ([]int, map[int]time.Time, error) {
+	ids := make([]int, 0)
+	changed := make(map[int]time.Time)
+	for _, query := range opts.queries() {
+		q := query.clone().ChangedBetween(since, time.Time{})
+		q.Set("include_fields", "id", "last_change_time")
+		it := c.SearchIterContext(ctx, q, opts.PageSize)
+		for it.Next() {
+			bug := it.Bug()
+			if _, ok := changed[bug.ID]; ok {
+				continue
+			}
+			if last, ok := seen[bug.ID]; ok && last.Equal(bug.LastChangeTime) {
+				continue
+			}
+			changed[bug.ID] = bug.LastChangeTime
+			ids = append(ids, bug.ID)
+		}
+		if err := it.Err(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return ids, changed, nil
+}
+
+// Sync finds the bugs changed since cursor, fetches them (along with
+// comments, attachments and history, as set in opts) and passes them to
+// sink. It returns the cursor for the next run, or the cursor passed and an
+// error if the run didn't complete. Bugs emitted before an error will be
+// emitted again in the next run.
+func (c *Client) Sync(ctx context.Context, cursor SyncCursor, opts SyncOptions, sink SyncSink) (SyncCursor, error) {
+	overlap := opts.Overlap
+	if overlap <= 0 {
+		overlap = DefaultSyncOverlap
+	}
+	var since time.Time
+	if !cursor.HighWater.IsZero() {
+		since = cursor.HighWater.Add(-overlap)
+	}
+
+	ids, changed, err := c.changedBugs(ctx, &opts, since, cursor.Seen)
+	if err != nil {
+		return cursor, err
+	}
+
+	getOpts := GetBugsOptions{
+		WithComments:    opts.WithComments,
+		WithAttachments: opts.WithAttachments,
+		ChunkSize:       opts.PageSize,
+	}
+	for _, chunk := range chunkIds(ids, opts.PageSize) {
+		bugs, err := c.GetBugsContext(ctx, chunk, getOpts)
+		// Bugs that became private or were removed after the search are
+		// skipped and left out of the cursor
+		var missingErr *MissingBugsError
+		if errors.As(err, &missingErr) {
+			for id := range missingErr.Errors {
+				delete(changed, id)
+			}
+		} else if err != nil {
+			return cursor, err
+		}
+		histories := make(map[int][]HistoryEntry)
+		if opts.WithHistory && len(bugs) > 0 {
+			found := make([]int, 0, len(bugs))
+			for i := range bugs {
+				found = append(found, bugs[i].ID)
+			}
+			bugHistories, err := c.GetHistoryContext(ctx, found, since)
+			if err != nil {
+				return cursor, err
+			}
+			for _, history := range bugHistories {
+				histories[history.ID] = history.History
+			}
+		}
+		for i := range bugs {
+			var history []HistoryEntry
+			if opts.WithHistory {
+				history = histories[bugs[i].ID]
+				if history == nil {
+					history = make([]HistoryEntry, 0)
+				}
+			}
+			if err := sink.PutBug(&bugs[i], history); err != nil {
+				return cursor, err
+			}
+			// The full fetch may have a newer change than the search
+			changed[bugs[i].ID] = bugs[i].LastChangeTime
+		}
+	}
+
+	next := SyncCursor{HighWater: cursor.HighWater, Seen: make(map[int]time.Time)}
+	for _, last := range changed {
+		if last.After(next.HighWater) {
+			next.HighWater = last
+		}
+	}
+	threshold := next.HighWater.Add(-overlap)
+	for _, seen := range []map[int]time.Time{cursor.Seen, changed} {
+		for id, last := range seen {
+			if !last.Before(threshold) {
+				next.Seen[id] = last
+			}
+		}
+	}
+	return next, nil
+}
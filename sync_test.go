@@ -0,0 +1,172 @@
+package bugzilla_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	bugzilla "github.com/bhdn/go-bugzilla-rest"
+	. "gopkg.in/check.v1"
+)
+
+// fakeSyncServer keeps bugs and their last change times and answers
+// searches filtered by delta_ts, bug fetches and history requests
+type fakeSyncServer struct {
+	mu      sync.Mutex
+	changes map[int]time.Time
+	// private bugs are found by searches but can't be fetched
+	private map[int]bool
+}
+
+func (f *fakeSyncServer) set(id int, when time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.changes[id] = when
+}
+
+func (f *fakeSyncServer) bugDoc(id int) string {
+	return fmt.Sprintf(`{"id": %d, "last_change_time": "%s"}`, id, f.changes[id].Format(time.RFC3339))
+}
+
+func (f *fakeSyncServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	query := r.URL.Query()
+	docs := []string{}
+	switch {
+	case r.URL.Path == "/rest/bug":
+		var since time.Time
+		afterID := 0
+		for i := 1; query.Get(fmt.Sprintf("f%d", i)) != ""; i++ {
+			v := query.Get(fmt.Sprintf("v%d", i))
+			switch query.Get(fmt.Sprintf("f%d", i)) {
+			case "delta_ts":
+				since, _ = time.Parse(time.RFC3339, v)
+			case "bug_id":
+				afterID, _ = strconv.Atoi(v)
+			}
+		}
+		ids := []int{}
+		for id, when := range f.changes {
+			if id > afterID && !when.Before(since) {
+				ids = append(ids, id)
+			}
+		}
+		sort.Ints(ids)
+		for _, id := range ids {
+			docs = append(docs, f.bugDoc(id))
+		}
+		fmt.Fprintf(w, `{"bugs": [%s]}`, strings.Join(docs, ","))
+	case strings.HasSuffix(r.URL.Path, "/history"):
+		for _, id := range query["ids"] {
+			n, _ := strconv.Atoi(id)
+			if f.private[n] {
+				http.Error(w, sampleError, http.StatusBadRequest)
+				return
+			}
+			docs = append(docs, fmt.Sprintf(`{"id": %s, "history": [{"who": "user1@foobarcorp.example.com", "changes": []}]}`, id))
+		}
+		fmt.Fprintf(w, `{"bugs": [%s]}`, strings.Join(docs, ","))
+	default:
+		faults := []string{}
+		for _, id := range query["ids"] {
+			n, _ := strconv.Atoi(id)
+			if f.private[n] {
+				faults = append(faults, fmt.Sprintf(`{"id": %d, "faultCode": 102, "faultString": "Not authorized"}`, n))
+				continue
+			}
+			docs = append(docs, f.bugDoc(n))
+		}
+		fmt.Fprintf(w, `{"bugs": [%s], "faults": [%s]}`, strings.Join(docs, ","), strings.Join(faults, ","))
+	}
+}
+
+func (cs *clientSuite) TestSync(c *C) {
+	base := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	fake := &fakeSyncServer{changes: map[int]time.Time{
+		1: base,
+		2: base.Add(time.Minute),
+		3: base.Add(2 * time.Minute),
+	}}
+	ts0 := httptest.NewServer(fake)
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	var emitted []int
+	sink := bugzilla.SyncSinkFunc(func(bug *bugzilla.Bug, history []bugzilla.HistoryEntry) error {
+		c.Check(history, HasLen, 1)
+		emitted = append(emitted, bug.ID)
+		return nil
+	})
+	opts := bugzilla.SyncOptions{Products: []string{"Enterprise Frobnicator 9000.1"}, WithHistory: true, PageSize: 2}
+	cursor, err := bz.Sync(context.Background(), bugzilla.SyncCursor{}, opts, sink)
+	c.Assert(err, IsNil)
+	c.Check(emitted, DeepEquals, []int{1, 2, 3})
+	c.Check(cursor.HighWater, Equals, base.Add(2*time.Minute))
+
+	// Nothing changed, the bugs inside the overlap window are not emitted
+	// again
+	emitted = nil
+	cursor, err = bz.Sync(context.Background(), cursor, opts, sink)
+	c.Assert(err, IsNil)
+	c.Check(emitted, HasLen, 0)
+
+	// A change that landed late, with an older timestamp, is caught by the
+	// overlap
+	fake.set(1, base.Add(90*time.Second))
+	fake.set(4, base.Add(3*time.Minute))
+	emitted = nil
+	cursor, err = bz.Sync(context.Background(), cursor, opts, sink)
+	c.Assert(err, IsNil)
+	c.Check(emitted, DeepEquals, []int{1, 4})
+	c.Check(cursor.HighWater, Equals, base.Add(3*time.Minute))
+}
+
+func (cs *clientSuite) TestSyncSinkError(c *C) {
+	base := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	fake := &fakeSyncServer{changes: map[int]time.Time{1: base}}
+	ts0 := httptest.NewServer(fake)
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	sinkErr := errors.New("database is gone")
+	sink := bugzilla.SyncSinkFunc(func(bug *bugzilla.Bug, history []bugzilla.HistoryEntry) error {
+		c.Check(history, IsNil)
+		return sinkErr
+	})
+	previous := bugzilla.SyncCursor{HighWater: base.Add(-time.Hour)}
+	cursor, err := bz.Sync(context.Background(), previous, bugzilla.SyncOptions{}, sink)
+	c.Check(err, Equals, sinkErr)
+	c.Check(cursor.HighWater, Equals, previous.HighWater)
+}
+
+func (cs *clientSuite) TestSyncMissingBugs(c *C) {
+	base := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	fake := &fakeSyncServer{
+		changes: map[int]time.Time{1: base, 2: base.Add(time.Minute), 3: base},
+		private: map[int]bool{2: true},
+	}
+	ts0 := httptest.NewServer(fake)
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	emitted := []int{}
+	sink := bugzilla.SyncSinkFunc(func(bug *bugzilla.Bug, history []bugzilla.HistoryEntry) error {
+		emitted = append(emitted, bug.ID)
+		return nil
+	})
+	previous := bugzilla.SyncCursor{HighWater: base.Add(-time.Hour)}
+	cursor, err := bz.Sync(context.Background(), previous, bugzilla.SyncOptions{WithHistory: true}, sink)
+	c.Assert(err, IsNil)
+	c.Check(emitted, DeepEquals, []int{1, 3})
+	c.Check(cursor.HighWater, Equals, base)
+	_, ok := cursor.Seen[2]
+	c.Check(ok, Equals, false)
+}
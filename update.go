@@ -0,0 +1,550 @@
+package bugzilla
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// BugUpdate is a set of changes to be applied to bugs with ApplyUpdate. It is
+// built by chaining its methods:
+//
+//	update := NewUpdate().SetSeverity("Critical").AddKeyword("regression").AddBlocks(1047068)
+//
+// Values are sent as given; for instance, SetPriority doesn't look up
+// PriorityMap as Update does.
+type BugUpdate struct {
+	upd   updBug
+	flags flagChanges
+}
+
+// NewUpdate returns an empty *BugUpdate
+func NewUpdate() *BugUpdate {
+	return &BugUpdate{}
+}
+
+func (u *BugUpdate) op(target **updOp) *updOp {
+	if *target == nil {
+		*target = &updOp{}
+	}
+	return *target
+}
+
+func (u *BugUpdate) intOp(target **updIntOp) *updIntOp {
+	if *target == nil {
+		*target = &updIntOp{}
+	}
+	return *target
+}
+
+func (u *BugUpdate) setString(target **string, value string) *BugUpdate {
+	*target = &value
+	return u
+}
+
+func (u *BugUpdate) setBool(target **bool, value bool) *BugUpdate {
+	*target = &value
+	return u
+}
+
+func (u *BugUpdate) setFloat(target **float64, value float64) *BugUpdate {
+	*target = &value
+	return u
+}
+
+// AddAlias adds aliases to the bug
+func (u *BugUpdate) AddAlias(aliases ...string) *BugUpdate {
+	op := u.op(&u.upd.Alias)
+	op.Add = append(op.Add, aliases...)
+	return u
+}
+
+// RemoveAlias removes aliases from the bug
+func (u *BugUpdate) RemoveAlias(aliases ...string) *BugUpdate {
+	op := u.op(&u.upd.Alias)
+	op.Remove = append(op.Remove, aliases...)
+	return u
+}
+
+// SetAlias replaces all the aliases of the bug
+func (u *BugUpdate) SetAlias(aliases ...string) *BugUpdate {
+	set := append([]string{}, aliases...)
+	u.op(&u.upd.Alias).Set = &set
+	return u
+}
+
+// AddBlocks adds bugs to the list of bugs blocked by this one
+func (u *BugUpdate) AddBlocks(ids ...int) *BugUpdate {
+	op := u.intOp(&u.upd.Blocks)
+	op.Add = append(op.Add, ids...)
+	return u
+}
+
+// RemoveBlocks removes bugs from the list of bugs blocked by this one
+func (u *BugUpdate) RemoveBlocks(ids ...int) *BugUpdate {
+	op := u.intOp(&u.upd.Blocks)
+	op.Remove = append(op.Remove, ids...)
+	return u
+}
+
+// SetBlocks replaces the list of bugs blocked by this one
+func (u *BugUpdate) SetBlocks(ids ...int) *BugUpdate {
+	set := append([]int{}, ids...)
+	u.intOp(&u.upd.Blocks).Set = &set
+	return u
+}
+
+// AddDependsOn adds bugs to the list of bugs this one depends on
+func (u *BugUpdate) AddDependsOn(ids ...int) *BugUpdate {
+	op := u.intOp(&u.upd.DependsOn)
+	op.Add = append(op.Add, ids...)
+	return u
+}
+
+// RemoveDependsOn removes bugs from the list of bugs this one depends on
+func (u *BugUpdate) RemoveDependsOn(ids ...int) *BugUpdate {
+	op := u.intOp(&u.upd.DependsOn)
+	op.Remove = append(op.Remove, ids...)
+	return u
+}
+
+// SetDependsOn replaces the list of bugs this one depends on
+func (u *BugUpdate) SetDependsOn(ids ...int) *BugUpdate {
+	set := append([]int{}, ids...)
+	u.intOp(&u.upd.DependsOn).Set = &set
+	return u
+}
+
+// AddCC adds users to the CC list
+func (u *BugUpdate) AddCC(emails ...string) *BugUpdate {
+	op := u.op(&u.upd.CC)
+	op.Add = append(op.Add, emails...)
+	return u
+}
+
+// RemoveCC removes users from the CC list
+func (u *BugUpdate) RemoveCC(emails ...string) *BugUpdate {
+	op := u.op(&u.upd.CC)
+	op.Remove = append(op.Remove, emails...)
+	return u
+}
+
+// AddGroups adds the bug to groups
+func (u *BugUpdate) AddGroups(groups ...string) *BugUpdate {
+	op := u.op(&u.upd.Groups)
+	op.Add = append(op.Add, groups...)
+	return u
+}
+
+// RemoveGroups removes the bug from groups
+func (u *BugUpdate) RemoveGroups(groups ...string) *BugUpdate {
+	op := u.op(&u.upd.Groups)
+	op.Remove = append(op.Remove, groups...)
+	return u
+}
+
+// AddKeyword adds keywords to the bug
+func (u *BugUpdate) AddKeyword(keywords ...string) *BugUpdate {
+	op := u.op(&u.upd.Keywords)
+	op.Add = append(op.Add, keywords...)
+	return u
+}
+
+// RemoveKeyword removes keywords from the bug
+func (u *BugUpdate) RemoveKeyword(keywords ...string) *BugUpdate {
+	op := u.op(&u.upd.Keywords)
+	op.Remove = append(op.Remove, keywords...)
+	return u
+}
+
+// SetKeywords replaces all the keywords of the bug
+func (u *BugUpdate) SetKeywords(keywords ...string) *BugUpdate {
+	set := append([]string{}, keywords...)
+	u.op(&u.upd.Keywords).Set = &set
+	return u
+}
+
+// AddSeeAlso adds URLs to the see also list
+func (u *BugUpdate) AddSeeAlso(urls ...string) *BugUpdate {
+	op := u.op(&u.upd.SeeAlso)
+	op.Add = append(op.Add, urls...)
+	return u
+}
+
+// RemoveSeeAlso removes URLs from the see also list
+func (u *BugUpdate) RemoveSeeAlso(urls ...string) *BugUpdate {
+	op := u.op(&u.upd.SeeAlso)
+	op.Remove = append(op.Remove, urls...)
+	return u
+}
+
+// SetCCAccessible sets whether users in the CC list can see the bug even if
+// they are not in its groups
+func (u *BugUpdate) SetCCAccessible(accessible bool) *BugUpdate {
+	return u.setBool(&u.upd.IsCCAccessible, accessible)
+}
+
+// SetCreatorAccessible sets whether the reporter can see the bug even if
+// they are not in its groups
+func (u *BugUpdate) SetCreatorAccessible(accessible bool) *BugUpdate {
+	return u.setBool(&u.upd.IsCreatorAccessible, accessible)
+}
+
+// ResetAssignedTo sets the assignee back to the default of the component
+func (u *BugUpdate) ResetAssignedTo() *BugUpdate {
+	return u.setBool(&u.upd.ResetAssignedTo, true)
+}
+
+// ResetQAContact sets the QA contact back to the default of the component
+func (u *BugUpdate) ResetQAContact() *BugUpdate {
+	return u.setBool(&u.upd.ResetQaContact, true)
+}
+
+// AddComment adds a comment to the bug
+func (u *BugUpdate) AddComment(text string, private bool) *BugUpdate {
+	u.upd.Comment = &commentChange{Body: text, IsPrivate: private}
+	return u
+}
+
+// SetAssignedTo sets the assignee
+func (u *BugUpdate) SetAssignedTo(email string) *BugUpdate {
+	return u.setString(&u.upd.AssignedTo, email)
+}
+
+// SetClassification sets the classification
+func (u *BugUpdate) SetClassification(classification string) *BugUpdate {
+	return u.setString(&u.upd.Classification, classification)
+}
+
+// SetComponent sets the component
+func (u *BugUpdate) SetComponent(component string) *BugUpdate {
+	return u.setString(&u.upd.Component, component)
+}
+
+// SetDupeOf marks the bug as a duplicate of another one
+func (u *BugUpdate) SetDupeOf(id int) *BugUpdate {
+	u.upd.DupeOf = id
+	return u
+}
+
+// SetOpSys sets the operating system
+func (u *BugUpdate) SetOpSys(opSys string) *BugUpdate {
+	return u.setString(&u.upd.OpSys, opSys)
+}
+
+// SetPlatform sets the platform
+func (u *BugUpdate) SetPlatform(platform string) *BugUpdate {
+	return u.setString(&u.upd.Platform, platform)
+}
+
+// SetPriority sets the priority, which must be the full name, as in
+// "P2 - High"
+func (u *BugUpdate) SetPriority(priority string) *BugUpdate {
+	return u.setString(&u.upd.Priority, priority)
+}
+
+// SetProduct sets the product. Bugzilla usually requires the component,
+// version and target milestone to be set along with it.
+func (u *BugUpdate) SetProduct(product string) *BugUpdate {
+	return u.setString(&u.upd.Product, product)
+}
+
+// SetQAContact sets the QA contact
+func (u *BugUpdate) SetQAContact(email string) *BugUpdate {
+	return u.setString(&u.upd.QAContact, email)
+}
+
+// SetResolution sets the resolution
+func (u *BugUpdate) SetResolution(resolution string) *BugUpdate {
+	return u.setString(&u.upd.Resolution, resolution)
+}
+
+// SetSeverity sets the severity
+func (u *BugUpdate) SetSeverity(severity string) *BugUpdate {
+	return u.setString(&u.upd.Severity, severity)
+}
+
+// SetStatus sets the status
+func (u *BugUpdate) SetStatus(status string) *BugUpdate {
+	return u.setString(&u.upd.Status, status)
+}
+
+// SetSummary sets the summary
+func (u *BugUpdate) SetSummary(summary string) *BugUpdate {
+	return u.setString(&u.upd.Summary, summary)
+}
+
+// SetTargetMilestone sets the target milestone
+func (u *BugUpdate) SetTargetMilestone(milestone string) *BugUpdate {
+	return u.setString(&u.upd.TargetMilestone, milestone)
+}
+
+// SetURL sets the URL
+func (u *BugUpdate) SetURL(url string) *BugUpdate {
+	return u.setString(&u.upd.URL, url)
+}
+
+// SetVersion sets the version
+func (u *BugUpdate) SetVersion(version string) *BugUpdate {
+	return u.setString(&u.upd.Version, version)
+}
+
+// SetWhiteboard sets the status whiteboard
+func (u *BugUpdate) SetWhiteboard(whiteboard string) *BugUpdate {
+	return u.setString(&u.upd.Whiteboard, whiteboard)
+}
+
+// SetDeadline sets the deadline, in the YYYY-MM-DD format
+func (u *BugUpdate) SetDeadline(deadline string) *BugUpdate {
+	return u.setString(&u.upd.Deadline, deadline)
+}
+
+// SetEstimatedTime sets the estimated time, in hours
+func (u *BugUpdate) SetEstimatedTime(hours float64) *BugUpdate {
+	return u.setFloat(&u.upd.EstimatedTime, hours)
+}
+
+// SetRemainingTime sets the remaining time, in hours
+func (u *BugUpdate) SetRemainingTime(hours float64) *BugUpdate {
+	return u.setFloat(&u.upd.RemainingTime, hours)
+}
+
+// AddWorkTime adds hours to the time worked on the bug
+func (u *BugUpdate) AddWorkTime(hours float64) *BugUpdate {
+	return u.setFloat(&u.upd.WorkTime, hours)
+}
+
+// forBugs returns a copy of the update to be sent for the given bugs
+func (u *BugUpdate) forBugs(ids []int) *updBug {
+	up := u.upd
+	up.Ids = append(make([]int, 0, len(ids)), ids...)
+	up.Flags = u.flags.changes
+	return &up
+}
+
+// validate returns the first error found while building the update
+func (u *BugUpdate) validate() error {
+	return u.flags.validate()
+}
+
+// ApplyUpdate applies update to the bug id
+func (c *Client) ApplyUpdate(id int, update *BugUpdate) (*UpdateResponse, error) {
+	return c.ApplyUpdateContext(context.Background(), id, update)
+}
+
+// ApplyUpdateContext is ApplyUpdate with a context.Context
+func (c *Client) ApplyUpdateContext(ctx context.Context, id int, update *BugUpdate) (*UpdateResponse, error) {
+	if err := update.validate(); err != nil {
+		return nil, err
+	}
+	return c.putUpdate(ctx, update.forBugs([]int{id}))
+}
+
+// BulkUpdateError is returned by UpdateMany when some of the bugs could not
+// be updated. Errors has the error of each of them.
+type BulkUpdateError struct {
+	Errors map[int]error
+}
+
+func (e *BulkUpdateError) Error() string {
+	ids := make([]int, 0, len(e.Errors))
+	for id := range e.Errors {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return fmt.Sprintf("Cannot update %d bug(s), bug %d: %v", len(ids), ids[0], e.Errors[ids[0]])
+}
+
+// isRejection tells whether err is Bugzilla refusing a request because of
+// some of its bugs, as opposed to a failure somewhere in between, such as a
+// proxy timing out, after which the request may have been applied anyway.
+// Failures that apply to the whole request, as authentication errors (codes
+// 300-399 and 410, or 401 Unauthorized) and 429 Too Many Requests, are not
+// rejections either, as retrying bug by bug would fail the same way.
+func isRejection(err error) bool {
+	var bzErr BugzillaError
+	if !errors.As(err, &bzErr) {
+		return false
+	}
+	if errors.Is(bzErr, ErrInvalidAPIKey) ||
+		(bzErr.Code >= 300 && bzErr.Code <= 399) || bzErr.Code == 410 ||
+		bzErr.HTTPStatus == http.StatusUnauthorized ||
+		bzErr.HTTPStatus == http.StatusTooManyRequests {
+		return false
+	}
+	return bzErr.Code != 0 || (bzErr.HTTPStatus >= 400 && bzErr.HTTPStatus <= 499)
+}
+
+// UpdateMany applies update to all the bugs in ids, sending one request for
+// each DefaultChunkSize bugs. It returns the responses of the bugs updated,
+// in the order of ids, and a *BulkUpdateError if any of them failed.
+//
+// Bugzilla rejects a request as a whole when one of its bugs can't be
+// changed, so the bugs of a rejected chunk are retried one by one to find
+// out which ones fail. Chunks failing for other reasons, such as a gateway
+// error, are not retried, as they may have been applied.
+func (c *Client) UpdateMany(ids []int, update *BugUpdate) ([]UpdateResponse, error) {
+	return c.UpdateManyContext(context.Background(), ids, update)
+}
+
+// UpdateManyContext is UpdateMany with a context.Context
+func (c *Client) UpdateManyContext(ctx context.Context, ids []int, update *BugUpdate) ([]UpdateResponse, error) {
+	if err := update.validate(); err != nil {
+		return nil, err
+	}
+	responses := make(map[int]UpdateResponse, len(ids))
+	failed := make(map[int]error)
+	for _, chunk := range chunkIds(ids, DefaultChunkSize) {
+		if err := ctx.Err(); err != nil {
+			for _, id := range chunk {
+				failed[id] = ConnectionError{err}
+			}
+			continue
+		}
+		chunkResponses, err := c.putUpdates(ctx, update.forBugs(chunk))
+		if err != nil && len(chunk) > 1 && isRejection(err) {
+			for _, id := range chunk {
+				response, err := c.putUpdate(ctx, update.forBugs([]int{id}))
+				if err != nil {
+					failed[id] = err
+					continue
+				}
+				responses[id] = *response
+			}
+			continue
+		}
+		if err != nil {
+			for _, id := range chunk {
+				failed[id] = err
+			}
+			continue
+		}
+		for _, response := range chunkResponses {
+			responses[response.Id] = response
+		}
+	}
+
+	result := make([]UpdateResponse, 0, len(responses))
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if response, ok := responses[id]; ok && !seen[id] {
+			seen[id] = true
+			result = append(result, response)
+		}
+	}
+	if len(failed) > 0 {
+		return result, &BulkUpdateError{Errors: failed}
+	}
+	return result, nil
+}
+
+type updAttachment struct {
+	Ids []int `json:"ids"`
+
+	Comment    *string      `json:"comment,omitempty"`
+	IsObsolete *bool        `json:"is_obsolete,omitempty"`
+	IsPrivate  *bool        `json:"is_private,omitempty"`
+	Summary    *string      `json:"summary,omitempty"`
+	Flags      []flagChange `json:"flags,omitempty"`
+}
+
+// AttachmentUpdate is a set of changes to be applied to an attachment with
+// UpdateAttachment, built like BugUpdate
+type AttachmentUpdate struct {
+	upd   updAttachment
+	flags flagChanges
+}
+
+// NewAttachmentUpdate returns an empty *AttachmentUpdate
+func NewAttachmentUpdate() *AttachmentUpdate {
+	return &AttachmentUpdate{}
+}
+
+// AddComment adds a comment to the bug about the change
+func (u *AttachmentUpdate) AddComment(text string) *AttachmentUpdate {
+	u.upd.Comment = &text
+	return u
+}
+
+// SetObsolete sets whether the attachment is obsolete
+func (u *AttachmentUpdate) SetObsolete(obsolete bool) *AttachmentUpdate {
+	u.upd.IsObsolete = &obsolete
+	return u
+}
+
+// SetPrivate sets whether the attachment is private
+func (u *AttachmentUpdate) SetPrivate(private bool) *AttachmentUpdate {
+	u.upd.IsPrivate = &private
+	return u
+}
+
+// SetSummary sets the description of the attachment
+func (u *AttachmentUpdate) SetSummary(summary string) *AttachmentUpdate {
+	u.upd.Summary = &summary
+	return u
+}
+
+type updateAttachmentResponse struct {
+	Attachments []UpdateResponse `json:"attachments"`
+}
+
+// UpdateAttachment applies update to the attachment id. Id in the response
+// is the ID of the attachment.
+func (c *Client) UpdateAttachment(id int, update *AttachmentUpdate) (*UpdateResponse, error) {
+	return c.UpdateAttachmentContext(context.Background(), id, update)
+}
+
+// UpdateAttachmentContext is UpdateAttachment with a context.Context
+func (c *Client) UpdateAttachmentContext(ctx context.Context, id int, update *AttachmentUpdate) (*UpdateResponse, error) {
+	if err := update.flags.validate(); err != nil {
+		return nil, err
+	}
+	up := update.upd
+	up.Ids = []int{id}
+	up.Flags = update.flags.changes
+	url, err := c.getAttachmentURL(id, map[string]string{})
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(&up)
+	if err != nil {
+		return nil, RequestError{fmt.Errorf("Cannot build update: %v", err)}
+	}
+	resp, err := c.put(ctx, url, "application/json", body)
+	if err != nil {
+		return nil, err
+	}
+	var result updateAttachmentResponse
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, ConnectionError{err}
+	}
+	if len(result.Attachments) != 1 {
+		return nil, fmt.Errorf("Got an unexpected number of update responses: %v", result.Attachments)
+	}
+	c.invalidateAttachment(ctx, id)
+	return &result.Attachments[0], nil
+}
+
+// invalidateAttachment drops the cached bug of the attachment id, which has
+// to be looked up
+func (c *Client) invalidateAttachment(ctx context.Context, id int) {
+	if _, ok := c.cacher.(CacheInvalidator); !ok {
+		return
+	}
+	url, err := c.getAttachmentURL(id, map[string]string{"include_fields": "bug_id"})
+	if err != nil {
+		return
+	}
+	body, err := c.fetch(ctx, url)
+	if err != nil {
+		return
+	}
+	attachments, err := c.decodeDirectAttachments(body, []int{id})
+	if err != nil || len(attachments) != 1 {
+		return
+	}
+	c.invalidateBug(attachments[0].BugId, CacheAttachments)
+}
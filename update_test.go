@@ -0,0 +1,207 @@
+package bugzilla_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+
+	bugzilla "github.com/bhdn/go-bugzilla-rest"
+	"github.com/kinbiko/jsonassert"
+	. "gopkg.in/check.v1"
+)
+
+const emptyUpdateResponse = `{"bugs": [{"alias": [], "changes": {}, "id": 101234, "last_change_time": "2023-05-09T09:53:05Z"}]}`
+
+func (cs *clientSuite) TestApplyUpdate(c *C) {
+	ts0, queries, _, processBug := makeBugzillaServerWithChannels()
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	update := bugzilla.NewUpdate().
+		SetProduct("Frobnicator").
+		SetComponent("Core").
+		SetTargetMilestone("2.0").
+		SetSeverity("Critical").
+		AddKeyword("regression", "TRETA").
+		RemoveKeyword("TRETA_ADDRESSED").
+		AddBlocks(1000, 1001).
+		SetDependsOn(999).
+		AddCC("user1@foobarcorp.example.com").
+		AddSeeAlso("https://bugs.example.com/1").
+		RemoveGroups("foobarcorponly").
+		SetCCAccessible(false).
+		ResetQAContact().
+		SetEstimatedTime(2.5).
+		AddComment("Moving to 2.0", true)
+
+	processBug <- `{"bugs": [{"alias": [], "changes": {"severity": {"added": "Critical", "removed": "Major"}}, "id": 101234, "last_change_time": "2023-05-09T09:53:05Z"}]}`
+	result, err := bz.ApplyUpdate(101234, update)
+	c.Assert(err, IsNil)
+	c.Check(result.Id, Equals, 101234)
+	c.Check(result.Changes["severity"].Added, Equals, "Critical")
+
+	ja := jsonassert.New(c)
+	ja.Assertf(<-queries, `{
+		"ids": [101234],
+		"product": "Frobnicator",
+		"component": "Core",
+		"target_milestone": "2.0",
+		"severity": "Critical",
+		"keywords": {"add": ["regression", "TRETA"], "remove": ["TRETA_ADDRESSED"]},
+		"blocks": {"add": [1000, 1001]},
+		"depends_on": {"set": [999]},
+		"cc": {"add": ["user1@foobarcorp.example.com"]},
+		"see_also": {"add": ["https://bugs.example.com/1"]},
+		"groups": {"remove": ["foobarcorponly"]},
+		"is_cc_accessible": false,
+		"reset_qa_contact": true,
+		"estimated_time": 2.5,
+		"comment": {"body": "Moving to 2.0", "is_private": true}
+	}`)
+}
+
+func (cs *clientSuite) TestApplyUpdateReusable(c *C) {
+	ts0, queries, _, processBug := makeBugzillaServerWithChannels()
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	update := bugzilla.NewUpdate().SetKeywords().SetStatus("CONFIRMED")
+	ja := jsonassert.New(c)
+	for i := 0; i < 2; i++ {
+		processBug <- emptyUpdateResponse
+		_, err := bz.ApplyUpdate(101234, update)
+		c.Assert(err, IsNil)
+		ja.Assertf(<-queries, `{"ids": [101234], "keywords": {"set": []}, "status": "CONFIRMED"}`)
+	}
+}
+
+// makeBulkUpdateServer accepts updates of any bug but denied, rejecting the
+// whole request when denied is in it, as Bugzilla does
+func makeBulkUpdateServer(c *C, denied int) (*httptest.Server, *[][]int) {
+	var mu sync.Mutex
+	requests := make([][]int, 0)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Ids []int `json:"ids"`
+		}
+		c.Assert(json.NewDecoder(r.Body).Decode(&body), IsNil)
+		mu.Lock()
+		requests = append(requests, body.Ids)
+		mu.Unlock()
+		bugs := make([]string, 0, len(body.Ids))
+		for _, id := range body.Ids {
+			if id == denied {
+				http.Error(w, sampleError, http.StatusBadRequest)
+				return
+			}
+			bugs = append(bugs, fmt.Sprintf(`{"alias": [], "changes": {"target_milestone": {"added": "2.0", "removed": "1.0"}}, "id": %d, "last_change_time": "2023-05-09T09:53:05Z"}`, id))
+		}
+		fmt.Fprintf(w, `{"bugs": [%s]}`, strings.Join(bugs, ","))
+	}))
+	return ts, &requests
+}
+
+func (cs *clientSuite) TestUpdateMany(c *C) {
+	ts0, requests := makeBulkUpdateServer(c, 0)
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	ids := make([]int, 0, 250)
+	for id := 1; id <= 250; id++ {
+		ids = append(ids, id)
+	}
+	result, err := bz.UpdateMany(ids, bugzilla.NewUpdate().SetTargetMilestone("2.0"))
+	c.Assert(err, IsNil)
+	c.Assert(result, HasLen, 250)
+	for i, response := range result {
+		c.Check(response.Id, Equals, ids[i])
+	}
+	c.Assert(*requests, HasLen, 3)
+	c.Check((*requests)[0], HasLen, 100)
+	c.Check((*requests)[2], DeepEquals, ids[200:])
+}
+
+func (cs *clientSuite) TestUpdateManyPartialFailure(c *C) {
+	ts0, requests := makeBulkUpdateServer(c, 150)
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	ids := make([]int, 0, 250)
+	for id := 1; id <= 250; id++ {
+		ids = append(ids, id)
+	}
+	result, err := bz.UpdateMany(ids, bugzilla.NewUpdate().SetTargetMilestone("2.0"))
+	c.Assert(err, NotNil)
+	var bulkErr *bugzilla.BulkUpdateError
+	c.Assert(errors.As(err, &bulkErr), Equals, true)
+	c.Assert(bulkErr.Errors, HasLen, 1)
+	c.Check(errors.Is(bulkErr.Errors[150], bugzilla.ErrAccessDenied), Equals, true)
+	c.Check(err, ErrorMatches, "Cannot update 1 bug\\(s\\), bug 150: .*")
+
+	c.Assert(result, HasLen, 249)
+	c.Check(result[148].Id, Equals, 149)
+	c.Check(result[149].Id, Equals, 151)
+	// The rejected chunk is retried one bug at a time
+	c.Check(*requests, HasLen, 3+100)
+}
+
+func (cs *clientSuite) TestUpdateManyConnectionError(c *C) {
+	ts0, _ := makeBulkUpdateServer(c, 0)
+	ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	result, err := bz.UpdateMany([]int{1, 2, 3}, bugzilla.NewUpdate().SetTargetMilestone("2.0"))
+	c.Check(result, HasLen, 0)
+	var bulkErr *bugzilla.BulkUpdateError
+	c.Assert(errors.As(err, &bulkErr), Equals, true)
+	c.Assert(bulkErr.Errors, HasLen, 3)
+	c.Check(bulkErr.Errors[2], FitsTypeOf, bugzilla.ConnectionError{})
+}
+
+func (cs *clientSuite) TestUpdateManyGatewayError(c *C) {
+	var mu sync.Mutex
+	requests := 0
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		http.Error(w, "<html>Bad Gateway</html>", http.StatusBadGateway)
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	result, err := bz.UpdateMany([]int{1, 2, 3}, bugzilla.NewUpdate().AddComment("Moving to 2.0", false))
+	c.Check(result, HasLen, 0)
+	var bulkErr *bugzilla.BulkUpdateError
+	c.Assert(errors.As(err, &bulkErr), Equals, true)
+	c.Assert(bulkErr.Errors, HasLen, 3)
+	c.Check(bulkErr.Errors[2], FitsTypeOf, bugzilla.BugzillaError{})
+	// The chunk may have been applied behind the gateway, so it is not
+	// retried bug by bug
+	c.Check(requests, Equals, 1)
+}
+
+func (cs *clientSuite) TestUpdateManyInvalidAPIKey(c *C) {
+	var mu sync.Mutex
+	requests := 0
+	ts0 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		http.Error(w, `{"code": 306, "error": true, "message": "The API key you specified is invalid."}`, http.StatusBadRequest)
+	}))
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	_, err := bz.UpdateMany([]int{1, 2, 3}, bugzilla.NewUpdate().SetTargetMilestone("2.0"))
+	var bulkErr *bugzilla.BulkUpdateError
+	c.Assert(errors.As(err, &bulkErr), Equals, true)
+	c.Assert(bulkErr.Errors, HasLen, 3)
+	c.Check(errors.Is(bulkErr.Errors[1], bugzilla.ErrInvalidAPIKey), Equals, true)
+	// The whole request is refused, so it is not retried bug by bug
+	c.Check(requests, Equals, 1)
+}
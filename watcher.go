@@ -0,0 +1,263 @@
+package bugzilla
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Event is something that happened to a bug, as found by a Watcher. It is
+// one of BugCreated, FieldChanged, CommentAdded, AttachmentAdded,
+// FlagRequested, FlagChanged or FlagCleared.
+type Event interface {
+	BugID() int
+}
+
+// BugCreated is sent for bugs filed after the Watcher started
+type BugCreated struct {
+	Bug *Bug
+}
+
+// FieldChanged is sent when a field of the bug changes. List fields, such as
+// cc and keywords, have their items separated by ", ".
+type FieldChanged struct {
+	ID    int
+	Field string
+	Old   string
+	New   string
+}
+
+// CommentAdded is sent for each new comment
+type CommentAdded struct {
+	ID      int
+	Comment Comment
+}
+
+// AttachmentAdded is sent for each new attachment
+type AttachmentAdded struct {
+	ID         int
+	Attachment Attachment
+}
+
+// FlagRequested is sent when a flag is set to "?"
+type FlagRequested struct {
+	ID   int
+	Flag Flag
+}
+
+// FlagChanged is sent when a flag is set to anything other than "?", as in
+// a request being granted. Old is zeroed for flags that didn't exist.
+type FlagChanged struct {
+	ID  int
+	Old Flag
+	New Flag
+}
+
+// FlagCleared is sent when a flag is removed from the bug
+type FlagCleared struct {
+	ID   int
+	Flag Flag
+}
+
+func (e BugCreated) BugID() int      { return e.Bug.ID }
+func (e FieldChanged) BugID() int    { return e.ID }
+func (e CommentAdded) BugID() int    { return e.ID }
+func (e AttachmentAdded) BugID() int { return e.ID }
+func (e FlagRequested) BugID() int   { return e.ID }
+func (e FlagChanged) BugID() int     { return e.ID }
+func (e FlagCleared) BugID() int     { return e.ID }
+
+// bugEvents compares two snapshots of a bug
+func bugEvents(old, new *Bug) []Event {
+	diff := Diff(old, new)
+	events := make([]Event, 0)
+	for _, field := range diff.Fields {
+		events = append(events, FieldChanged{ID: new.ID, Field: field.Field, Old: field.Old, New: field.New})
+	}
+	for _, list := range diffLists {
+		for _, changed := range diff.Lists {
+			if changed.Field == list.name {
+				o := strings.Join(list.value(old), ", ")
+				n := strings.Join(list.value(new), ", ")
+				events = append(events, FieldChanged{ID: new.ID, Field: list.name, Old: o, New: n})
+			}
+		}
+	}
+	for _, comment := range diff.NewComments {
+		events = append(events, CommentAdded{ID: new.ID, Comment: comment})
+	}
+	for _, attachment := range diff.NewAttachments {
+		events = append(events, AttachmentAdded{ID: new.ID, Attachment: attachment})
+	}
+	for _, flag := range diff.Flags {
+		switch {
+		case flag.New == nil:
+			events = append(events, FlagCleared{ID: new.ID, Flag: *flag.Old})
+		case flag.New.Status == "?":
+			events = append(events, FlagRequested{ID: new.ID, Flag: *flag.New})
+		case flag.Old == nil:
+			events = append(events, FlagChanged{ID: new.ID, New: *flag.New})
+		default:
+			events = append(events, FlagChanged{ID: new.ID, Old: *flag.Old, New: *flag.New})
+		}
+	}
+	return events
+}
+
+// DefaultWatchInterval is the polling interval of a Watcher when
+// WatcherOptions.Interval is not set
+const DefaultWatchInterval = 5 * time.Minute
+
+// WatchCursor is the state of a Watcher, which can be encoded as JSON and
+// passed to a new Watcher to resume from where the last one stopped
+type WatchCursor struct {
+	// HighWater is the newest last_change_time seen
+	HighWater time.Time `json:"high_water"`
+	// Bugs has the last snapshot of each watched bug
+	Bugs map[int]*Bug `json:"bugs"`
+}
+
+// WatcherOptions sets what a Watcher polls: the bugs matching Query or the
+// bugs in IDs
+type WatcherOptions struct {
+	Query *SearchQuery
+	IDs   []int
+
+	Interval time.Duration
+	// Cursor is the state from a previous Watcher. Without it, the first
+	// poll only takes the snapshots that are used for comparison.
+	Cursor *WatchCursor
+}
+
+// Watcher polls bugs, comparing them with the previous snapshots to find
+// what changed
+type Watcher struct {
+	client *Client
+	opts   WatcherOptions
+	cursor *WatchCursor
+}
+
+// NewWatcher returns a *Watcher for the bugs set in opts
+func (c *Client) NewWatcher(opts WatcherOptions) *Watcher {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultWatchInterval
+	}
+	return &Watcher{client: c, opts: opts, cursor: opts.Cursor}
+}
+
+// Cursor returns the current state of the watcher, to be persisted
+func (w *Watcher) Cursor() *WatchCursor {
+	return w.cursor
+}
+
+func (w *Watcher) query() *SearchQuery {
+	if w.opts.Query != nil {
+		return w.opts.Query.clone()
+	}
+	ids := make([]string, 0, len(w.opts.IDs))
+	for _, id := range w.opts.IDs {
+		ids = append(ids, fmt.Sprintf("%d", id))
+	}
+	return NewSearchQuery().Set("id", strings.Join(ids, ","))
+}
+
+// Poll checks the bugs once and returns the events found
+func (w *Watcher) Poll(ctx context.Context) ([]Event, error) {
+	if w.opts.Query == nil && len(w.opts.IDs) == 0 {
+		return nil, RequestError{fmt.Errorf("nothing to watch: no query or IDs set")}
+	}
+
+	q := w.query().Set("include_fields", "id", "last_change_time")
+	it := w.client.SearchIterContext(ctx, q, 0)
+	current := make(map[int]bool)
+	changed := make([]int, 0)
+	for it.Next() {
+		bug := it.Bug()
+		current[bug.ID] = true
+		if w.cursor != nil {
+			if old, ok := w.cursor.Bugs[bug.ID]; ok && old.LastChangeTime.Equal(bug.LastChangeTime) {
+				continue
+			}
+		}
+		changed = append(changed, bug.ID)
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	opts := GetBugsOptions{WithComments: true, WithAttachments: true}
+	bugs := make([]Bug, 0)
+	if len(changed) > 0 {
+		var err error
+		bugs, err = w.client.GetBugsContext(ctx, changed, opts)
+		// Bugs that became private or were removed after the search are
+		// dropped from the cursor, as if they were not found by it
+		var missingErr *MissingBugsError
+		if errors.As(err, &missingErr) {
+			for id := range missingErr.Errors {
+				delete(current, id)
+			}
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	next := &WatchCursor{Bugs: make(map[int]*Bug, len(current))}
+	if w.cursor != nil {
+		next.HighWater = w.cursor.HighWater
+		for id, bug := range w.cursor.Bugs {
+			if current[id] {
+				next.Bugs[id] = bug
+			}
+		}
+	}
+
+	events := make([]Event, 0)
+	for i := range bugs {
+		bug := &bugs[i]
+		if w.cursor != nil {
+			if old, ok := w.cursor.Bugs[bug.ID]; ok {
+				events = append(events, bugEvents(old, bug)...)
+			} else if bug.CreationTime.After(w.cursor.HighWater) {
+				events = append(events, BugCreated{Bug: bug})
+			}
+		}
+		next.Bugs[bug.ID] = bug
+		if bug.LastChangeTime.After(next.HighWater) {
+			next.HighWater = bug.LastChangeTime
+		}
+	}
+	w.cursor = next
+
+	return events, nil
+}
+
+// Run polls the bugs at every interval, sending the events found to
+// events, until ctx is done or an error happens
+func (w *Watcher) Run(ctx context.Context, events chan<- Event) error {
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+	for {
+		found, err := w.Poll(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		for _, event := range found {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package bugzilla_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	bugzilla "github.com/bhdn/go-bugzilla-rest"
+	. "gopkg.in/check.v1"
+)
+
+// fakeBugServer serves bugs kept in memory, supporting searches by a list
+// of IDs
+type fakeBugServer struct {
+	mu   sync.Mutex
+	bugs map[int]bugzilla.Bug
+	// private bugs are found by searches but can't be fetched
+	private map[int]bool
+}
+
+func (f *fakeBugServer) update(id int, change func(bug *bugzilla.Bug)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	bug := f.bugs[id]
+	change(&bug)
+	bug.LastChangeTime = bug.LastChangeTime.Add(time.Minute)
+	f.bugs[id] = bug
+}
+
+func (f *fakeBugServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	query := r.URL.Query()
+	var ids []int
+	if r.URL.Path == "/rest/bug" {
+		for id := range f.bugs {
+			if strings.Contains(","+query.Get("id")+",", ","+strconv.Itoa(id)+",") {
+				ids = append(ids, id)
+			}
+		}
+		if after := query.Get("v1"); after != "" {
+			n, _ := strconv.Atoi(after)
+			filtered := ids[:0]
+			for _, id := range ids {
+				if id > n {
+					filtered = append(filtered, id)
+				}
+			}
+			ids = filtered
+		}
+		sort.Ints(ids)
+	} else {
+		for _, id := range query["ids"] {
+			n, _ := strconv.Atoi(id)
+			ids = append(ids, n)
+		}
+	}
+
+	comments := map[string]map[string][]bugzilla.Comment{}
+	attachments := map[string][]bugzilla.Attachment{}
+	bugs := []bugzilla.Bug{}
+	faults := []map[string]interface{}{}
+	for _, id := range ids {
+		if f.private[id] && r.URL.Path != "/rest/bug" {
+			faults = append(faults, map[string]interface{}{"id": id, "faultCode": 102, "faultString": "Not authorized"})
+			continue
+		}
+		bug := f.bugs[id]
+		comments[strconv.Itoa(id)] = map[string][]bugzilla.Comment{"comments": bug.Comments}
+		attachments[strconv.Itoa(id)] = bug.Attachments
+		bug.Comments = nil
+		bug.Attachments = nil
+		bugs = append(bugs, bug)
+	}
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/comment"):
+		json.NewEncoder(w).Encode(map[string]interface{}{"bugs": comments})
+	case strings.HasSuffix(r.URL.Path, "/attachment"):
+		json.NewEncoder(w).Encode(map[string]interface{}{"bugs": attachments})
+	default:
+		json.NewEncoder(w).Encode(map[string]interface{}{"bugs": bugs, "faults": faults})
+	}
+}
+
+func newFakeBugServer() *fakeBugServer {
+	created := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	return &fakeBugServer{bugs: map[int]bugzilla.Bug{
+		10: {
+			ID:             10,
+			Status:         "NEW",
+			Keywords:       []string{"TRETA"},
+			CreationTime:   created,
+			LastChangeTime: created,
+			Comments:       []bugzilla.Comment{{ID: 100, BugID: 10, Count: 0, Text: "Description"}},
+			Flags: []bugzilla.Flag{
+				{ID: 1, Name: "needinfo", Status: "?", Requestee: "user1@foobarcorp.example.com"},
+				{ID: 2, Name: "review", Status: "?", Requestee: "user2@foobarcorp.example.com"},
+			},
+		},
+	}}
+}
+
+func (cs *clientSuite) TestWatcherPoll(c *C) {
+	fake := newFakeBugServer()
+	ts0 := httptest.NewServer(fake)
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	watcher := bz.NewWatcher(bugzilla.WatcherOptions{IDs: []int{10, 11}})
+	events, err := watcher.Poll(context.Background())
+	c.Assert(err, IsNil)
+	c.Check(events, HasLen, 0)
+
+	events, err = watcher.Poll(context.Background())
+	c.Assert(err, IsNil)
+	c.Check(events, HasLen, 0)
+
+	fake.update(10, func(bug *bugzilla.Bug) {
+		bug.Status = "ASSIGNED"
+		bug.Keywords = append(bug.Keywords, "FIXED_UPSTREAM")
+		bug.Comments = append(bug.Comments, bugzilla.Comment{ID: 101, BugID: 10, Count: 1, Text: "On it"})
+		bug.Attachments = append(bug.Attachments, bugzilla.Attachment{ID: 500, BugId: 10})
+		bug.Flags = []bugzilla.Flag{
+			{ID: 2, Name: "review", Status: "+"},
+			{ID: 3, Name: "needinfo", Status: "?", Requestee: "user3@foobarcorp.example.com"},
+		}
+	})
+	fake.mu.Lock()
+	fake.bugs[11] = bugzilla.Bug{ID: 11, CreationTime: time.Date(2023, 5, 2, 0, 0, 0, 0, time.UTC)}
+	fake.mu.Unlock()
+
+	events, err = watcher.Poll(context.Background())
+	c.Assert(err, IsNil)
+	c.Assert(events, HasLen, 8)
+	c.Check(events[:7], DeepEquals, []bugzilla.Event{
+		bugzilla.FieldChanged{ID: 10, Field: "status", Old: "NEW", New: "ASSIGNED"},
+		bugzilla.FieldChanged{ID: 10, Field: "keywords", Old: "TRETA", New: "TRETA, FIXED_UPSTREAM"},
+		bugzilla.CommentAdded{ID: 10, Comment: bugzilla.Comment{ID: 101, BugID: 10, Count: 1, Text: "On it"}},
+		bugzilla.AttachmentAdded{ID: 10, Attachment: bugzilla.Attachment{ID: 500, BugId: 10}},
+		bugzilla.FlagChanged{ID: 10,
+			Old: bugzilla.Flag{ID: 2, Name: "review", Status: "?", Requestee: "user2@foobarcorp.example.com"},
+			New: bugzilla.Flag{ID: 2, Name: "review", Status: "+"}},
+		bugzilla.FlagRequested{ID: 10, Flag: bugzilla.Flag{ID: 3, Name: "needinfo", Status: "?", Requestee: "user3@foobarcorp.example.com"}},
+		bugzilla.FlagCleared{ID: 10, Flag: bugzilla.Flag{ID: 1, Name: "needinfo", Status: "?", Requestee: "user1@foobarcorp.example.com"}},
+	})
+
+	c.Check(events[7].BugID(), Equals, 11)
+	c.Check(events[7], FitsTypeOf, bugzilla.BugCreated{})
+
+	events, err = watcher.Poll(context.Background())
+	c.Assert(err, IsNil)
+	c.Check(events, HasLen, 0)
+}
+
+func (cs *clientSuite) TestWatcherMissingBugs(c *C) {
+	fake := newFakeBugServer()
+	ts0 := httptest.NewServer(fake)
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	watcher := bz.NewWatcher(bugzilla.WatcherOptions{IDs: []int{10, 11}})
+	_, err := watcher.Poll(context.Background())
+	c.Assert(err, IsNil)
+
+	// Bug 11 shows up in the search but becomes private before the fetch
+	fake.update(10, func(bug *bugzilla.Bug) { bug.Status = "ASSIGNED" })
+	fake.mu.Lock()
+	fake.bugs[11] = bugzilla.Bug{ID: 11, CreationTime: time.Date(2023, 5, 2, 0, 0, 0, 0, time.UTC)}
+	fake.private = map[int]bool{11: true}
+	fake.mu.Unlock()
+
+	events, err := watcher.Poll(context.Background())
+	c.Assert(err, IsNil)
+	c.Check(events, DeepEquals, []bugzilla.Event{
+		bugzilla.FieldChanged{ID: 10, Field: "status", Old: "NEW", New: "ASSIGNED"},
+	})
+	_, ok := watcher.Cursor().Bugs[11]
+	c.Check(ok, Equals, false)
+	c.Check(watcher.Cursor().Bugs[10].Status, Equals, "ASSIGNED")
+}
+
+func (cs *clientSuite) TestWatcherCursor(c *C) {
+	fake := newFakeBugServer()
+	ts0 := httptest.NewServer(fake)
+	defer ts0.Close()
+	bz := makeClient(ts0.URL)
+
+	watcher := bz.NewWatcher(bugzilla.WatcherOptions{IDs: []int{10}})
+	_, err := watcher.Poll(context.Background())
+	c.Assert(err, IsNil)
+	encoded, err := json.Marshal(watcher.Cursor())
+	c.Assert(err, IsNil)
+
+	fake.update(10, func(bug *bugzilla.Bug) { bug.Status = "ASSIGNED" })
+
+	var cursor bugzilla.WatchCursor
+	c.Assert(json.Unmarshal(encoded, &cursor), IsNil)
+	watcher = bz.NewWatcher(bugzilla.WatcherOptions{IDs: []int{10}, Cursor: &cursor, Interval: time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan bugzilla.Event)
+	done := make(chan error)
+	go func() { done <- watcher.Run(ctx, events) }()
+	event := <-events
+	cancel()
+	c.Check(errors.Is(<-done, context.Canceled), Equals, true)
+	c.Check(event, DeepEquals, bugzilla.FieldChanged{ID: 10, Field: "status", Old: "NEW", New: "ASSIGNED"})
+}